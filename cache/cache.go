@@ -0,0 +1,216 @@
+// Package cache manages commands installed under the base command path.
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+// lastUsedFile is a file whose modification time is the last-use time of an installed command
+const lastUsedFile = ".last_used"
+
+// metadataFiles are the files which sd-cmd keeps in the directory of a version besides the command itself
+var metadataFiles = map[string]bool{specFile: true, lockFile: true, lastUsedFile: true}
+
+// Entry is an installed version of a command
+type Entry struct {
+	Namespace string
+	Name      string
+	Version   string
+	Path      string
+	Size      int64
+	LastUsed  time.Time
+	// Metadata is nil if the spec has never been persisted or can not be read
+	Metadata *Metadata
+}
+
+// FullName returns namespace/name@version of the entry
+func (e *Entry) FullName() string {
+	return fmt.Sprintf("%s/%s@%s", e.Namespace, e.Name, e.Version)
+}
+
+// Dir returns the directory which the version of the command is installed in
+func Dir(namespace, name, version string) string {
+	return filepath.Join(config.BaseCommandPath, namespace, name, version)
+}
+
+// MarkUsed records the current time as the last-use time of the installed command
+func MarkUsed(namespace, name, version string) error {
+	filePath := filepath.Join(Dir(namespace, name, version), lastUsedFile)
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("Failed to create last-use file: %v", err)
+	}
+	file.Close()
+
+	now := time.Now()
+	if err := os.Chtimes(filePath, now, now); err != nil {
+		return fmt.Errorf("Failed to update last-use time: %v", err)
+	}
+	return nil
+}
+
+func subDirs(dirPath string) ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]os.FileInfo, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() {
+			dirs = append(dirs, info)
+		}
+	}
+	return dirs, nil
+}
+
+// newEntry returns the entry of the installed version of the command.
+// It returns nil if the directory has the metadata only, which is the case of a command not installed such as docker format.
+func newEntry(namespace, name string, versionDir os.FileInfo) (*Entry, error) {
+	entry := &Entry{
+		Namespace: namespace,
+		Name:      name,
+		Version:   versionDir.Name(),
+		Path:      Dir(namespace, name, versionDir.Name()),
+		LastUsed:  versionDir.ModTime(),
+	}
+
+	isInstalled := false
+	err := filepath.Walk(entry.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			entry.Size += info.Size()
+		}
+		if path != entry.Path && !metadataFiles[info.Name()] {
+			isInstalled = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get size of %s: %v", entry.FullName(), err)
+	}
+	if !isInstalled {
+		return nil, nil
+	}
+
+	if info, err := os.Stat(filepath.Join(entry.Path, lastUsedFile)); err == nil {
+		entry.LastUsed = info.ModTime()
+	}
+
+	// the metadata which can not be read is rewritten on the next exec, so it is not an error here
+	if metadata, err := ReadMetadata(namespace, name, entry.Version); err == nil {
+		entry.Metadata = metadata
+	}
+	return entry, nil
+}
+
+// List returns all installed commands sorted by namespace, name and version.
+// The commands which have the persisted spec only are not listed.
+func List() ([]*Entry, error) {
+	var entries []*Entry
+
+	namespaces, err := subDirs(config.BaseCommandPath)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read command directory: %v", err)
+	}
+
+	for _, namespace := range namespaces {
+		names, err := subDirs(filepath.Join(config.BaseCommandPath, namespace.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read command directory: %v", err)
+		}
+		for _, name := range names {
+			versions, err := subDirs(filepath.Join(config.BaseCommandPath, namespace.Name(), name.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read command directory: %v", err)
+			}
+			for _, version := range versions {
+				entry, err := newEntry(namespace.Name(), name.Name(), version)
+				if err != nil {
+					return nil, err
+				}
+				if entry != nil {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// Remove deletes the installed command and its parent directories if they become empty
+func Remove(entry *Entry) error {
+	if err := os.RemoveAll(entry.Path); err != nil {
+		return fmt.Errorf("Failed to remove %s: %v", entry.FullName(), err)
+	}
+	if err := removeRefs(entry.Namespace, entry.Name, entry.Version); err != nil {
+		return fmt.Errorf("Failed to remove tags of %s: %v", entry.FullName(), err)
+	}
+	if err := removeVersions(entry.Namespace, entry.Name); err != nil {
+		return fmt.Errorf("Failed to remove versions of %s/%s: %v", entry.Namespace, entry.Name, err)
+	}
+
+	// ignore error on removing parent directories intentionally, they may not be empty
+	nameDir := filepath.Dir(entry.Path)
+	os.Remove(nameDir)
+	os.Remove(filepath.Dir(nameDir))
+	return nil
+}
+
+// PruneOptions represents conditions of pruning.
+// A zero value of each option means no limit.
+type PruneOptions struct {
+	MaxAge   time.Duration
+	MaxSize  int64
+	KeepLast int
+}
+
+// SelectPrunable returns entries which should be removed to satisfy opts.
+// Less recently used entries are removed first.
+func SelectPrunable(entries []*Entry, opts PruneOptions, now time.Time) []*Entry {
+	sorted := make([]*Entry, len(entries))
+	copy(sorted, entries)
+	// most recently used first
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastUsed.After(sorted[j].LastUsed)
+	})
+
+	var prunable, kept []*Entry
+	versionCount := make(map[string]int)
+	for _, entry := range sorted {
+		command := entry.Namespace + "/" + entry.Name
+		versionCount[command]++
+
+		switch {
+		case opts.MaxAge > 0 && now.Sub(entry.LastUsed) > opts.MaxAge:
+			prunable = append(prunable, entry)
+		case opts.KeepLast > 0 && versionCount[command] > opts.KeepLast:
+			prunable = append(prunable, entry)
+		default:
+			kept = append(kept, entry)
+		}
+	}
+
+	if opts.MaxSize > 0 {
+		var total int64
+		for _, entry := range kept {
+			total += entry.Size
+		}
+		for i := len(kept) - 1; i >= 0 && total > opts.MaxSize; i-- {
+			prunable = append(prunable, kept[i])
+			total -= kept[i].Size
+		}
+	}
+	return prunable
+}
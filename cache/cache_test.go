@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+const (
+	dummyNameSpace = "foo-dummy"
+	dummyName      = "name-dummy"
+	dummyVersion   = "1.0.1"
+)
+
+func setup() {
+	config.BaseCommandPath, _ = ioutil.TempDir("", "sd-cmd_cache")
+}
+
+func teardown() {
+	os.RemoveAll(config.BaseCommandPath)
+}
+
+// install creates a dummy installed command which is used at lastUsed
+func install(t *testing.T, namespace, name, version string, size int, lastUsed time.Time) {
+	dir := Dir(namespace, name, version)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		t.Fatalf("failed to create command directory: %v", err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "sd-step"), make([]byte, size), 0777)
+	assert.Nil(t, MarkUsed(namespace, name, version))
+	os.Chtimes(filepath.Join(dir, lastUsedFile), lastUsed, lastUsed)
+}
+
+func TestDir(t *testing.T) {
+	assert.Equal(t, filepath.Join(config.BaseCommandPath, "foo-dummy/name-dummy/1.0.1"), Dir(dummyNameSpace, dummyName, dummyVersion))
+}
+
+func TestListAndRemove(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	lastUsed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	install(t, dummyNameSpace, dummyName, "1.0.0", 10, lastUsed)
+	install(t, dummyNameSpace, dummyName, dummyVersion, 20, lastUsed)
+
+	entries, err := List()
+	assert.Nil(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "foo-dummy/name-dummy@1.0.0", entries[0].FullName())
+		assert.Equal(t, int64(10), entries[0].Size)
+		assert.True(t, lastUsed.Equal(entries[0].LastUsed))
+		assert.Nil(t, entries[0].Metadata)
+		assert.Equal(t, "foo-dummy/name-dummy@1.0.1", entries[1].FullName())
+	}
+
+	// MarkUsed updates the last-use time
+	assert.Nil(t, MarkUsed(dummyNameSpace, dummyName, dummyVersion))
+	entries, _ = List()
+	assert.True(t, entries[1].LastUsed.After(lastUsed))
+
+	// remove all versions
+	for _, entry := range entries {
+		assert.Nil(t, Remove(entry))
+	}
+	entries, err = List()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 0)
+	_, err = os.Stat(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestListWithoutCommandPath(t *testing.T) {
+	path := config.BaseCommandPath
+	defer func() { config.BaseCommandPath = path }()
+
+	config.BaseCommandPath = filepath.Join(path, "not_exist")
+	entries, err := List()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestSelectPrunable(t *testing.T) {
+	now := time.Now()
+	entry := func(name, version string, size int64, age time.Duration) *Entry {
+		return &Entry{Namespace: dummyNameSpace, Name: name, Version: version, Size: size, LastUsed: now.Add(-age)}
+	}
+	entries := []*Entry{
+		entry("a", "1.0.0", 100, 48*time.Hour),
+		entry("a", "1.0.1", 100, 2*time.Hour),
+		entry("a", "1.0.2", 100, time.Hour),
+		entry("b", "2.0.0", 300, 3*time.Hour),
+	}
+	fullNames := func(entries []*Entry) (names []string) {
+		for _, e := range entries {
+			names = append(names, e.FullName())
+		}
+		return
+	}
+
+	// by age
+	prunable := SelectPrunable(entries, PruneOptions{MaxAge: 24 * time.Hour}, now)
+	assert.Equal(t, []string{"foo-dummy/a@1.0.0"}, fullNames(prunable))
+
+	// by keep-last-N
+	prunable = SelectPrunable(entries, PruneOptions{KeepLast: 1}, now)
+	assert.Equal(t, []string{"foo-dummy/a@1.0.1", "foo-dummy/a@1.0.0"}, fullNames(prunable))
+
+	// by total size, least recently used first
+	prunable = SelectPrunable(entries, PruneOptions{MaxSize: 250}, now)
+	assert.Equal(t, []string{"foo-dummy/a@1.0.0", "foo-dummy/b@2.0.0"}, fullNames(prunable))
+
+	// no limit
+	prunable = SelectPrunable(entries, PruneOptions{}, now)
+	assert.Len(t, prunable, 0)
+}
+
+func TestMain(m *testing.M) {
+	setup()
+	ret := m.Run()
+	teardown()
+	os.Exit(ret)
+}
+
+func TestListSkipsMetadataOnly(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	// the spec of a command which is not installed, such as docker format
+	spec := dummySpec(dummyVersion)
+	spec.Format = "docker"
+	assert.Nil(t, SaveSpec("stable", spec))
+	entries, err := List()
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	install(t, dummyNameSpace, dummyName, dummyVersion, 10, time.Now())
+	entries, err = List()
+	assert.Nil(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.NotNil(t, entries[0].Metadata)
+	}
+}
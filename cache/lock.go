@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockFile is a file to lock the version of the command across processes
+const lockFile = ".lock"
+
+// refsLockFile is a file to lock refs.json of the command across processes
+const refsLockFile = ".refs.lock"
+
+// Lock acquires an exclusive lock on the version of the command across processes.
+// It blocks until the other process releases the lock, and returns a function to release it.
+func Lock(namespace, name, version string) (unlock func(), err error) {
+	return lockFilePath(filepath.Join(Dir(namespace, name, version), lockFile), fmt.Sprintf("%s/%s@%s", namespace, name, version))
+}
+
+// lockRefs acquires an exclusive lock on refs.json of the command across processes
+func lockRefs(namespace, name string) (unlock func(), err error) {
+	return lockFilePath(filepath.Join(commandDir(namespace, name), refsLockFile), fmt.Sprintf("%s/%s", namespace, name))
+}
+
+func lockFilePath(filePath, target string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return nil, fmt.Errorf("Failed to create command directory: %v", err)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create lock file: %v", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to lock %s: %v", target, err)
+	}
+
+	return func() {
+		// closing the file releases the lock as well
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
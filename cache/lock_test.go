@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+func TestLock(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	unlock, err := Lock(dummyNameSpace, dummyName, dummyVersion)
+	assert.Nil(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := Lock(dummyNameSpace, dummyName, dummyVersion)
+		assert.Nil(t, err)
+		close(locked)
+		unlock()
+	}()
+
+	// the second lock waits for the first one
+	select {
+	case <-locked:
+		t.Errorf("the lock is acquired twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Errorf("the lock is not released")
+	}
+}
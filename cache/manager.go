@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Manager is a type to list and remove installed commands
+type Manager struct {
+	action  string
+	targets []string
+	opts    PruneOptions
+}
+
+// New generates new Manager.
+// args is expected as ["ls"], ["rm", "namespace/name[@version]"...] or ["prune", options...]
+func New(args []string) (m *Manager, err error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("parameters are not enough")
+	}
+
+	m = &Manager{action: args[0]}
+	switch m.action {
+	case "ls":
+	case "rm":
+		m.targets = args[1:]
+		if len(m.targets) == 0 {
+			return nil, fmt.Errorf("no command to remove is specified")
+		}
+	case "prune":
+		m.opts, err = parsePruneCommand(args[1:])
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%v is invalid cache action", m.action)
+	}
+	return
+}
+
+func parsePruneCommand(args []string) (opts PruneOptions, err error) {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	fs.DurationVar(&opts.MaxAge, "max-age", 0, "Remove commands not used for this duration (e.g. 720h)")
+	maxSize := fs.String("max-size", "", "Remove least recently used commands until total size is under this size (e.g. 500M)")
+	fs.IntVar(&opts.KeepLast, "keep", 0, "Keep this number of recently used versions per command")
+
+	err = fs.Parse(args)
+	if err != nil {
+		return opts, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+
+	if *maxSize != "" {
+		opts.MaxSize, err = parseSize(*maxSize)
+		if err != nil {
+			return opts, err
+		}
+	}
+	if opts.MaxAge <= 0 && opts.MaxSize <= 0 && opts.KeepLast <= 0 {
+		return opts, fmt.Errorf("one of -max-age, -max-size or -keep is required")
+	}
+	return opts, nil
+}
+
+var sizeUnits = []string{"B", "K", "M", "G", "T"}
+
+// parseSize parses size such as 1024, 500K, 10M or 1G
+func parseSize(size string) (int64, error) {
+	s := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(size)), "B")
+	multiplier := int64(1)
+	for i := len(sizeUnits) - 1; i > 0; i-- {
+		if strings.HasSuffix(s, sizeUnits[i]) {
+			s = strings.TrimSuffix(s, sizeUnits[i])
+			multiplier = int64(1) << (10 * uint(i))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%v is invalid size", size)
+	}
+	return n * multiplier, nil
+}
+
+// formatSize formats size in a human-readable unit
+func formatSize(size int64) string {
+	value := float64(size)
+	for i, unit := range sizeUnits {
+		if value < 1024 || i == len(sizeUnits)-1 {
+			if i == 0 {
+				return fmt.Sprintf("%d%s", size, unit)
+			}
+			return fmt.Sprintf("%.1f%s", value, unit)
+		}
+		value /= 1024
+	}
+	return ""
+}
+
+func (m *Manager) list() error {
+	entries, err := List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tFORMAT\tSIZE\tLAST USED\tRESOLVED FROM")
+	for _, entry := range entries {
+		format, resolvedFrom := "-", "-"
+		if entry.Metadata != nil {
+			format = entry.Metadata.Spec.Format
+			var requested []string
+			for _, resolution := range entry.Metadata.Resolutions {
+				requested = append(requested, resolution.Requested)
+			}
+			if len(requested) > 0 {
+				resolvedFrom = strings.Join(requested, ",")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.FullName(), format, formatSize(entry.Size),
+			entry.LastUsed.Format(time.RFC3339), resolvedFrom)
+	}
+	return w.Flush()
+}
+
+// match checks that target (namespace/name or namespace/name@version) specifies the entry
+func match(target string, entry *Entry) bool {
+	if strings.Contains(target, "@") {
+		return target == entry.FullName()
+	}
+	return target == entry.Namespace+"/"+entry.Name
+}
+
+func (m *Manager) remove() error {
+	entries, err := List()
+	if err != nil {
+		return err
+	}
+
+	for _, target := range m.targets {
+		found := false
+		for _, entry := range entries {
+			if !match(target, entry) {
+				continue
+			}
+			found = true
+			if err := Remove(entry); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s\n", entry.FullName())
+		}
+		if !found {
+			return fmt.Errorf("%v is not installed", target)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) prune() error {
+	entries, err := List()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range SelectPrunable(entries, m.opts, time.Now()) {
+		if err := Remove(entry); err != nil {
+			return err
+		}
+		total += entry.Size
+		fmt.Printf("Removed %s (%s)\n", entry.FullName(), formatSize(entry.Size))
+	}
+	fmt.Printf("Total reclaimed space: %s\n", formatSize(total))
+	return nil
+}
+
+// Run executes the cache action
+func (m *Manager) Run() error {
+	switch m.action {
+	case "ls":
+		return m.list()
+	case "rm":
+		return m.remove()
+	default:
+		return m.prune()
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+func TestNew(t *testing.T) {
+	// success
+	m, err := New([]string{"ls"})
+	assert.Nil(t, err)
+	assert.Equal(t, "ls", m.action)
+
+	m, err = New([]string{"rm", "foo/bar@1.0.0", "foo/baz"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"foo/bar@1.0.0", "foo/baz"}, m.targets)
+
+	m, err = New([]string{"prune", "-max-age", "720h", "-max-size", "1G", "-keep", "3"})
+	assert.Nil(t, err)
+	assert.Equal(t, PruneOptions{MaxAge: 720 * time.Hour, MaxSize: 1 << 30, KeepLast: 3}, m.opts)
+
+	// failure
+	failureArgs := [][]string{
+		{},
+		{"unknown"},
+		{"rm"},
+		{"prune"},
+		{"prune", "-max-size", "1X"},
+		{"prune", "-invalid"},
+	}
+	for _, args := range failureArgs {
+		_, err = New(args)
+		assert.NotNil(t, err, "args: %v", args)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		size     string
+		expected int64
+	}{
+		{"1024", 1024},
+		{"10B", 10},
+		{"500K", 500 << 10},
+		{"10m", 10 << 20},
+		{"1GB", 1 << 30},
+	}
+	for _, c := range cases {
+		size, err := parseSize(c.size)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, size)
+	}
+
+	_, err := parseSize("-1")
+	assert.NotNil(t, err)
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512B", formatSize(512))
+	assert.Equal(t, "1.5K", formatSize(1536))
+	assert.Equal(t, "10.0M", formatSize(10<<20))
+}
+
+func TestRun(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	now := time.Now()
+	install(t, dummyNameSpace, dummyName, "1.0.0", 10, now.Add(-48*time.Hour))
+	install(t, dummyNameSpace, dummyName, dummyVersion, 10, now)
+	install(t, dummyNameSpace, "other", dummyVersion, 10, now)
+	spec := dummySpec(dummyVersion)
+	assert.Nil(t, SaveSpec("stable", spec))
+
+	// ls
+	m, _ := New([]string{"ls"})
+	assert.Nil(t, m.Run())
+
+	// prune
+	m, _ = New([]string{"prune", "-max-age", "24h"})
+	assert.Nil(t, m.Run())
+	entries, _ := List()
+	assert.Len(t, entries, 2)
+
+	// rm
+	m, _ = New([]string{"rm", "foo-dummy/name-dummy@1.0.1", "foo-dummy/other"})
+	assert.Nil(t, m.Run())
+	entries, _ = List()
+	assert.Len(t, entries, 0)
+
+	// failure. not installed
+	m, _ = New([]string{"rm", "foo-dummy/name-dummy"})
+	assert.NotNil(t, m.Run())
+}
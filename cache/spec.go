@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const (
+	// specFile is a file of the metadata next to the installed command
+	specFile = "spec.json"
+	// refsFile is a file of versions which tags and version ranges of the command are resolved to
+	refsFile = "refs.json"
+)
+
+// Metadata represents the command spec persisted next to the installed command
+type Metadata struct {
+	Spec      *util.CommandSpec `json:"spec"`
+	FetchedAt time.Time         `json:"fetchedAt"`
+	// Resolutions are tags and version ranges which were resolved to the version.
+	// They are not saved in spec.json, but filled on reading.
+	Resolutions []Resolution `json:"resolutions,omitempty"`
+}
+
+// Resolution represents a tag or version range resolved to an exact version
+type Resolution struct {
+	Requested  string    `json:"requested"`
+	Version    string    `json:"version"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+func commandDir(namespace, name string) string {
+	return filepath.Join(config.BaseCommandPath, namespace, name)
+}
+
+// writeJSON writes v as json so that nobody reads a half-written file
+func writeJSON(filePath string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert to json: %v", err)
+	}
+	return util.WriteFileAtomically(filePath, data, 0666)
+}
+
+func readJSON(filePath string, v interface{}) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func loadRefs(namespace, name string) (map[string]Resolution, error) {
+	refs := make(map[string]Resolution)
+	err := readJSON(filepath.Join(commandDir(namespace, name), refsFile), &refs)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Failed to read %s: %v", refsFile, err)
+	}
+	return refs, nil
+}
+
+// SaveSpec persists the spec fetched from Screwdriver API next to the installed command.
+// requested is the version, tag or version range which was resolved to the spec.
+func SaveSpec(requested string, spec *util.CommandSpec) error {
+	now := time.Now()
+	metadata := &Metadata{
+		Spec:      spec,
+		FetchedAt: now,
+	}
+	err := writeJSON(filepath.Join(Dir(spec.Namespace, spec.Name, spec.Version), specFile), metadata)
+	if err != nil {
+		return err
+	}
+	if requested == spec.Version {
+		return nil
+	}
+
+	// the other processes may update refs.json at the same time
+	unlock, err := lockRefs(spec.Namespace, spec.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	refs, err := loadRefs(spec.Namespace, spec.Name)
+	if err != nil {
+		return err
+	}
+	refs[requested] = Resolution{
+		Requested:  requested,
+		Version:    spec.Version,
+		ResolvedAt: now,
+	}
+	return writeJSON(filepath.Join(commandDir(spec.Namespace, spec.Name), refsFile), refs)
+}
+
+// ReadMetadata returns the metadata of namespace/name@version.
+// It returns an error which satisfies os.IsNotExist if the spec has never been persisted,
+// and an error if the persisted file has no spec, e.g. it is broken or written in an old format.
+func ReadMetadata(namespace, name, version string) (*Metadata, error) {
+	metadata := new(Metadata)
+	filePath := filepath.Join(Dir(namespace, name, version), specFile)
+	err := readJSON(filePath, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Spec == nil {
+		return nil, fmt.Errorf("%s has no command spec", filePath)
+	}
+
+	refs, err := loadRefs(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if ref.Version == version {
+			metadata.Resolutions = append(metadata.Resolutions, ref)
+		}
+	}
+	sort.Slice(metadata.Resolutions, func(i, j int) bool {
+		return metadata.Resolutions[i].Requested < metadata.Resolutions[j].Requested
+	})
+	return metadata, nil
+}
+
+// Resolve returns the metadata which namespace/name@requested was resolved to.
+// requested can be a version, or a tag or version range which was resolved before.
+func Resolve(namespace, name, requested string) (*Metadata, error) {
+	version := requested
+	refs, err := loadRefs(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if ref, ok := refs[requested]; ok {
+		version = ref.Version
+	}
+
+	metadata, err := ReadMetadata(namespace, name, version)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s/%s@%s has never been cached", namespace, name, requested)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %v", specFile, err)
+	}
+	return metadata, nil
+}
+
+// removeRefs removes tags and version ranges which are resolved to the version
+func removeRefs(namespace, name, version string) error {
+	unlock, err := lockRefs(namespace, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	refs, err := loadRefs(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	refsPath := filepath.Join(commandDir(namespace, name), refsFile)
+	for requested, ref := range refs {
+		if ref.Version == version {
+			delete(refs, requested)
+		}
+	}
+	if len(refs) == 0 {
+		if err := os.Remove(refsPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeJSON(refsPath, refs)
+}
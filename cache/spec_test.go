@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+func dummySpec(version string) *util.CommandSpec {
+	return &util.CommandSpec{
+		Namespace: dummyNameSpace,
+		Name:      dummyName,
+		Version:   version,
+		Format:    "binary",
+		Binary:    &util.Binary{File: "sd-step"},
+	}
+}
+
+func TestSaveSpecAndResolve(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	// save with exact version and tags
+	assert.Nil(t, SaveSpec("1.0.0", dummySpec("1.0.0")))
+	assert.Nil(t, SaveSpec("stable", dummySpec("1.0.0")))
+	assert.Nil(t, SaveSpec("latest", dummySpec(dummyVersion)))
+
+	for requested, expected := range map[string]string{
+		"1.0.0":      "1.0.0",
+		"stable":     "1.0.0",
+		"latest":     dummyVersion,
+		dummyVersion: dummyVersion,
+	} {
+		metadata, err := Resolve(dummyNameSpace, dummyName, requested)
+		if assert.Nil(t, err) {
+			assert.Equal(t, expected, metadata.Spec.Version)
+			assert.Equal(t, "sd-step", metadata.Spec.Binary.File)
+			assert.False(t, metadata.FetchedAt.IsZero())
+		}
+	}
+
+	// resolutions to the version
+	metadata, err := ReadMetadata(dummyNameSpace, dummyName, "1.0.0")
+	assert.Nil(t, err)
+	if assert.Len(t, metadata.Resolutions, 1) {
+		assert.Equal(t, "stable", metadata.Resolutions[0].Requested)
+		assert.Equal(t, "1.0.0", metadata.Resolutions[0].Version)
+	}
+	_, err = ReadMetadata(dummyNameSpace, dummyName, "0.0.1")
+	assert.True(t, os.IsNotExist(err))
+
+	// failure. never cached
+	_, err = Resolve(dummyNameSpace, dummyName, "beta")
+	assert.EqualError(t, err, "foo-dummy/name-dummy@beta has never been cached")
+
+	// tags are removed with the version
+	for _, version := range []string{"1.0.0", dummyVersion} {
+		ioutil.WriteFile(filepath.Join(Dir(dummyNameSpace, dummyName, version), "sd-step"), []byte("dummy"), 0777)
+	}
+	entries, _ := List()
+	assert.Nil(t, Remove(entries[0]))
+	_, err = Resolve(dummyNameSpace, dummyName, "stable")
+	assert.NotNil(t, err)
+	_, err = Resolve(dummyNameSpace, dummyName, "latest")
+	assert.Nil(t, err)
+	assert.Nil(t, Remove(entries[1]))
+	_, err = os.Stat(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSaveSpecConcurrently(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	// no resolution is lost by the others saved at the same time
+	requested := []string{"stable", "latest", "beta", "^1.0", "~1.0.1", "1.x", "1", ">=1.0.0"}
+	wg := sync.WaitGroup{}
+	for _, r := range requested {
+		wg.Add(1)
+		go func(r string) {
+			defer wg.Done()
+			assert.Nil(t, SaveSpec(r, dummySpec(dummyVersion)))
+		}(r)
+	}
+	wg.Wait()
+
+	refs, err := loadRefs(dummyNameSpace, dummyName)
+	assert.Nil(t, err)
+	assert.Len(t, refs, len(requested))
+}
+
+func TestReadMetadataWithoutSpec(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	install(t, dummyNameSpace, dummyName, dummyVersion, 10, time.Now())
+
+	// spec.json written in the old format or broken
+	for _, data := range []string{`{"namespace":"foo-dummy","name":"name-dummy","version":"1.0.1"}`, `{}`, `null`} {
+		ioutil.WriteFile(filepath.Join(Dir(dummyNameSpace, dummyName, dummyVersion), specFile), []byte(data), 0666)
+		_, err := ReadMetadata(dummyNameSpace, dummyName, dummyVersion)
+		assert.NotNil(t, err, data)
+		_, err = Resolve(dummyNameSpace, dummyName, dummyVersion)
+		assert.NotNil(t, err, data)
+
+		entries, err := List()
+		assert.Nil(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Nil(t, entries[0].Metadata)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// versionsFile is a file of the published versions of the command
+const versionsFile = "versions.json"
+
+// VersionList represents the published versions of a command fetched from Screwdriver API
+type VersionList struct {
+	Versions  []string  `json:"versions"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// SaveVersions persists the published versions of namespace/name
+func SaveVersions(namespace, name string, versions []string) error {
+	list := &VersionList{
+		Versions:  versions,
+		FetchedAt: time.Now(),
+	}
+	return writeJSON(filepath.Join(commandDir(namespace, name), versionsFile), list)
+}
+
+// LoadVersions returns the persisted versions of namespace/name.
+// It returns an error which satisfies os.IsNotExist if the versions have never been persisted.
+func LoadVersions(namespace, name string) (*VersionList, error) {
+	list := new(VersionList)
+	err := readJSON(filepath.Join(commandDir(namespace, name), versionsFile), list)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Failed to read %s: %v", versionsFile, err)
+	}
+	return list, nil
+}
+
+// removeVersions removes the persisted versions and the lock of refs.json if no version of namespace/name is installed
+func removeVersions(namespace, name string) error {
+	versions, err := subDirs(commandDir(namespace, name))
+	if err != nil || len(versions) > 0 {
+		// ignore error intentionally, the directory may be removed already
+		return nil
+	}
+	for _, file := range []string{versionsFile, refsLockFile} {
+		err = os.Remove(filepath.Join(commandDir(namespace, name), file))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
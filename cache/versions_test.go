@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+func TestSaveAndLoadVersions(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	// failure. never saved
+	_, err := LoadVersions(dummyNameSpace, dummyName)
+	assert.True(t, os.IsNotExist(err))
+
+	// success
+	versions := []string{"1.0.0", "1.1.0", "2.0.0"}
+	assert.Nil(t, SaveVersions(dummyNameSpace, dummyName, versions))
+	list, err := LoadVersions(dummyNameSpace, dummyName)
+	if assert.Nil(t, err) {
+		assert.Equal(t, versions, list.Versions)
+		assert.WithinDuration(t, time.Now(), list.FetchedAt, time.Minute)
+	}
+
+	// the versions are removed with the last installed version
+	install(t, dummyNameSpace, dummyName, "1.0.0", 10, time.Now())
+	install(t, dummyNameSpace, dummyName, "1.1.0", 10, time.Now())
+	entries, _ := List()
+	assert.Nil(t, Remove(entries[0]))
+	_, err = LoadVersions(dummyNameSpace, dummyName)
+	assert.Nil(t, err)
+	assert.Nil(t, Remove(entries[1]))
+	_, err = LoadVersions(dummyNameSpace, dummyName)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	assert.True(t, os.IsNotExist(err))
+}
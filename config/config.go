@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -20,6 +22,51 @@ var (
 	BaseCommandPath = "/opt/sd/commands/"
 	// DEBUG is flag of debug option
 	DEBUG bool
+	// Offline is flag of offline mode which executes commands without Screwdriver API and Store
+	Offline bool
+	// SigningKeyPath is path of the private key to sign commands on publishing
+	SigningKeyPath string
+	// TrustedKeysPath is path of the public keys to verify commands on executing
+	TrustedKeysPath string
+	// SignaturePolicy is how to handle unsigned commands ("enforce" or "warn")
+	SignaturePolicy = SignaturePolicyWarn
+	// VersionListTTL is how long the cached version list of a command is used to resolve version ranges without Screwdriver API
+	VersionListTTL = DefaultVersionListTTL
+	// LockfilePath is path of the lockfile which pins command versions
+	LockfilePath = DefaultLockfilePath
+	// TagHistoryPath is path of the file which records tag moves. It is under SD_ARTIFACTS_DIR if it is empty,
+	// so the history is shared across builds only when it points at shared storage.
+	TagHistoryPath string
+	// Report is flag of writing the execution report
+	Report bool
+	// CleanEnv is flag of running the command with the declared environment variables and the allowlist only
+	CleanEnv bool
+	// CaptureOutput is flag of capturing the output of the command into files
+	CaptureOutput bool
+	// CaptureTimestamps is flag of prefixing each line of the captured output with a timestamp
+	CaptureTimestamps bool
+	// CaptureMaxSize is the maximum size in bytes of each captured output file
+	CaptureMaxSize int64 = DefaultCaptureMaxSize
+	// EnvAllowlist is names of environment variables passed to the command in addition to the default ones
+	// when the environment is scrubbed
+	EnvAllowlist []string
+	// TerminationGracePeriod is how long a timed out command is waited for after SIGTERM before SIGKILL
+	TerminationGracePeriod = DefaultTerminationGracePeriod
+)
+
+const (
+	// SignaturePolicyEnforce refuses unsigned commands
+	SignaturePolicyEnforce = "enforce"
+	// SignaturePolicyWarn only warns unsigned commands
+	SignaturePolicyWarn = "warn"
+	// DefaultVersionListTTL is the default value of VersionListTTL. The version list is always fetched by default.
+	DefaultVersionListTTL = time.Duration(0)
+	// DefaultLockfilePath is the default value of LockfilePath
+	DefaultLockfilePath = "sd-cmd.lock"
+	// DefaultCaptureMaxSize is the default value of CaptureMaxSize
+	DefaultCaptureMaxSize = 10 * 1024 * 1024
+	// DefaultTerminationGracePeriod is the default value of TerminationGracePeriod
+	DefaultTerminationGracePeriod = 10 * time.Second
 )
 
 // LoadConfig sets config data
@@ -35,4 +82,38 @@ func LoadConfig() {
 		BaseCommandPath = os.Getenv("SD_BASE_COMMAND_PATH")
 	}
 	DEBUG, _ = strconv.ParseBool(os.Getenv("SD_CMD_DEBUG_LOG"))
+	Offline, _ = strconv.ParseBool(os.Getenv("SD_CMD_OFFLINE"))
+	Report, _ = strconv.ParseBool(os.Getenv("SD_CMD_REPORT"))
+	CaptureOutput, _ = strconv.ParseBool(os.Getenv("SD_CMD_CAPTURE_OUTPUT"))
+	CleanEnv, _ = strconv.ParseBool(os.Getenv("SD_CMD_CLEAN_ENV"))
+	CaptureTimestamps, _ = strconv.ParseBool(os.Getenv("SD_CMD_CAPTURE_TIMESTAMPS"))
+	CaptureMaxSize = DefaultCaptureMaxSize
+	if size, err := strconv.ParseInt(os.Getenv("SD_CMD_CAPTURE_MAX_SIZE"), 10, 64); err == nil && size >= 0 {
+		CaptureMaxSize = size
+	}
+	SigningKeyPath = os.Getenv("SD_CMD_SIGNING_KEY")
+	TrustedKeysPath = os.Getenv("SD_CMD_TRUSTED_KEYS")
+	SignaturePolicy = SignaturePolicyWarn
+	if os.Getenv("SD_CMD_SIGNATURE_POLICY") == SignaturePolicyEnforce {
+		SignaturePolicy = SignaturePolicyEnforce
+	}
+	VersionListTTL = DefaultVersionListTTL
+	if ttl, err := time.ParseDuration(os.Getenv("SD_CMD_VERSION_LIST_TTL")); err == nil {
+		VersionListTTL = ttl
+	}
+	LockfilePath = DefaultLockfilePath
+	if len(os.Getenv("SD_CMD_LOCKFILE")) != 0 {
+		LockfilePath = os.Getenv("SD_CMD_LOCKFILE")
+	}
+	TagHistoryPath = os.Getenv("SD_CMD_TAG_HISTORY")
+	EnvAllowlist = nil
+	for _, name := range strings.Split(os.Getenv("SD_CMD_ENV_ALLOWLIST"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			EnvAllowlist = append(EnvAllowlist, name)
+		}
+	}
+	TerminationGracePeriod = DefaultTerminationGracePeriod
+	if period, err := time.ParseDuration(os.Getenv("SD_CMD_TERMINATION_GRACE_PERIOD")); err == nil {
+		TerminationGracePeriod = period
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,6 +20,9 @@ const (
 	dummySDArtifactsDir = "dummy/sd/Artifacts/"
 	dummyCustomCmdPath  = "/opt/sd/commands/"
 	dummyDebug          = "true"
+	dummySigningKey     = "/dummy/signing.key"
+	dummyTrustedKeys    = "/dummy/trusted.keys"
+	dummyLockfile       = "/dummy/sd-cmd.lock"
 )
 
 func setEnv(key, value string) {
@@ -36,6 +40,20 @@ func setup() {
 	setEnv("SD_ARTIFACTS_DIR", dummySDArtifactsDir)
 	setEnv("SD_BASE_COMMAND_PATH", dummyCustomCmdPath)
 	setEnv("SD_CMD_DEBUG_LOG", dummyDebug)
+	setEnv("SD_CMD_OFFLINE", "true")
+	setEnv("SD_CMD_SIGNING_KEY", dummySigningKey)
+	setEnv("SD_CMD_TRUSTED_KEYS", dummyTrustedKeys)
+	setEnv("SD_CMD_SIGNATURE_POLICY", SignaturePolicyEnforce)
+	setEnv("SD_CMD_VERSION_LIST_TTL", "1h")
+	setEnv("SD_CMD_LOCKFILE", dummyLockfile)
+	setEnv("SD_CMD_TERMINATION_GRACE_PERIOD", "30s")
+	setEnv("SD_CMD_REPORT", "true")
+	setEnv("SD_CMD_TAG_HISTORY", "/tmp/tag-history.jsonl")
+	setEnv("SD_CMD_CLEAN_ENV", "true")
+	setEnv("SD_CMD_ENV_ALLOWLIST", "HTTP_PROXY, NO_PROXY,")
+	setEnv("SD_CMD_CAPTURE_OUTPUT", "true")
+	setEnv("SD_CMD_CAPTURE_TIMESTAMPS", "true")
+	setEnv("SD_CMD_CAPTURE_MAX_SIZE", "1024")
 }
 
 func teardown() {
@@ -53,13 +71,51 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, dummyCustomCmdPath, BaseCommandPath)
 	wantDebug, _ := strconv.ParseBool(dummyDebug)
 	assert.Equal(t, wantDebug, DEBUG)
+	assert.Equal(t, true, Offline)
+	assert.Equal(t, dummySigningKey, SigningKeyPath)
+	assert.Equal(t, dummyTrustedKeys, TrustedKeysPath)
+	assert.Equal(t, SignaturePolicyEnforce, SignaturePolicy)
+	assert.Equal(t, time.Hour, VersionListTTL)
+	assert.Equal(t, dummyLockfile, LockfilePath)
+	assert.Equal(t, 30*time.Second, TerminationGracePeriod)
+	assert.Equal(t, true, Report)
+	assert.Equal(t, "/tmp/tag-history.jsonl", TagHistoryPath)
+	assert.Equal(t, true, CleanEnv)
+	assert.Equal(t, []string{"HTTP_PROXY", "NO_PROXY"}, EnvAllowlist)
+	assert.Equal(t, true, CaptureOutput)
+	assert.Equal(t, true, CaptureTimestamps)
+	assert.Equal(t, int64(1024), CaptureMaxSize)
 
 	// check unset env
 	os.Unsetenv("SD_API_URL")
 	os.Unsetenv("SD_CMD_DEBUG_LOG")
+	os.Unsetenv("SD_CMD_SIGNATURE_POLICY")
+	os.Unsetenv("SD_CMD_OFFLINE")
+	os.Unsetenv("SD_CMD_VERSION_LIST_TTL")
+	os.Unsetenv("SD_CMD_LOCKFILE")
+	os.Unsetenv("SD_CMD_TERMINATION_GRACE_PERIOD")
+	os.Unsetenv("SD_CMD_REPORT")
+	os.Unsetenv("SD_CMD_TAG_HISTORY")
+	os.Unsetenv("SD_CMD_CLEAN_ENV")
+	os.Unsetenv("SD_CMD_ENV_ALLOWLIST")
+	os.Unsetenv("SD_CMD_CAPTURE_OUTPUT")
+	os.Unsetenv("SD_CMD_CAPTURE_TIMESTAMPS")
+	os.Unsetenv("SD_CMD_CAPTURE_MAX_SIZE")
 	LoadConfig()
 	assert.Equal(t, "", SDAPIURL)
 	assert.Equal(t, false, DEBUG)
+	assert.Equal(t, false, Offline)
+	assert.Equal(t, SignaturePolicyWarn, SignaturePolicy)
+	assert.Equal(t, DefaultVersionListTTL, VersionListTTL)
+	assert.Equal(t, DefaultLockfilePath, LockfilePath)
+	assert.Equal(t, DefaultTerminationGracePeriod, TerminationGracePeriod)
+	assert.Equal(t, false, Report)
+	assert.Equal(t, "", TagHistoryPath)
+	assert.Equal(t, false, CleanEnv)
+	assert.Nil(t, EnvAllowlist)
+	assert.Equal(t, false, CaptureOutput)
+	assert.Equal(t, false, CaptureTimestamps)
+	assert.Equal(t, int64(DefaultCaptureMaxSize), CaptureMaxSize)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,211 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/store"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// archiveRootName is the directory name which an archive is extracted into
+const archiveRootName = "archive"
+
+// Archive is an Archive Executor object
+type Archive struct {
+	Args []string
+	// from SD API
+	Spec  *util.CommandSpec
+	Store store.Store
+	// Note: this property is set after downloaing an archive via Store API
+	Command *store.Command
+}
+
+// NewArchive returns Archive object
+func NewArchive(spec *util.CommandSpec, args []string, isVerbose bool) (*Archive, error) {
+	if spec.Archive == nil {
+		return nil, fmt.Errorf("The archive is not specified")
+	}
+	if spec.Archive.Entrypoint == "" {
+		return nil, fmt.Errorf("The entrypoint of the archive is not specified")
+	}
+
+	storeapi := store.New(config.SDStoreURL, spec, config.SDToken)
+
+	storeapi.SetVerbose(isVerbose)
+
+	archive := &Archive{
+		Args:  args,
+		Spec:  spec,
+		Store: storeapi,
+	}
+	return archive, nil
+}
+
+func (a *Archive) getDirPath() string {
+	return cache.Dir(a.Spec.Namespace, a.Spec.Name, a.Spec.Version)
+}
+
+// getFilePath returns the path of the downloaded archive which is kept to verify the installation
+func (a *Archive) getFilePath() string {
+	if a.Spec.Archive.File == "" {
+		return filepath.Join(a.getDirPath(), a.Spec.Name+".archive")
+	}
+	return filepath.Join(a.getDirPath(), filepath.Base(a.Spec.Archive.File))
+}
+
+// getRootPath returns the directory which the archive is extracted into
+func (a *Archive) getRootPath() string {
+	return filepath.Join(a.getDirPath(), archiveRootName)
+}
+
+// getEntrypointPath returns the path of the entrypoint in the extracted archive
+func (a *Archive) getEntrypointPath() (string, error) {
+	path, err := util.SecureJoin(a.getRootPath(), a.Spec.Archive.Entrypoint)
+	if err != nil {
+		return "", fmt.Errorf("The entrypoint is invalid: %v", err)
+	}
+	return path, nil
+}
+
+// isInstalled returns true if the archive is downloaded and extracted.
+// The archive file is written after the extraction, so its existence means that the extraction is completed.
+func (a *Archive) isInstalled() bool {
+	fInfo, err := os.Stat(a.getFilePath())
+	if err != nil || fInfo.Size() == 0 {
+		return false
+	}
+	if _, err := os.Stat(a.getRootPath()); err != nil {
+		return false
+	}
+	if a.Spec.Archive.Sha256 != "" {
+		body, err := ioutil.ReadFile(a.getFilePath())
+		if err != nil {
+			return false
+		}
+		if err := verifyDigest(a.Spec.Archive.Sha256, body); err != nil {
+			lgr.Debug.Printf("installed archive command is broken: %v", err)
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Archive) download() error {
+	start := time.Now()
+	cmd, err := a.Store.GetCommand()
+	if err != nil {
+		return err
+	}
+	report.recordDownload(start, len(cmd.Body))
+	a.Command = cmd
+	return nil
+}
+
+func (a *Archive) install() error {
+	if err := verifyDigest(a.Spec.Archive.Sha256, a.Command.Body); err != nil {
+		return fmt.Errorf("Failed to verify downloaded command: %v", err)
+	}
+
+	dirPath := a.getDirPath()
+	if err := os.MkdirAll(dirPath, 0777); err != nil {
+		return fmt.Errorf("Failed to create command directory: %v", err)
+	}
+
+	// extract into a temporary directory and rename it so that nobody runs a half-extracted command
+	tempDir, err := ioutil.TempDir(dirPath, "extract")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary directory: %v", err)
+	}
+	// ignore error on directory remove intentionally
+	defer os.RemoveAll(tempDir)
+	if err := util.ExtractArchive(a.Command.Body, tempDir); err != nil {
+		return fmt.Errorf("Failed to extract archive: %v", err)
+	}
+	if err := os.RemoveAll(a.getRootPath()); err != nil {
+		return fmt.Errorf("Failed to remove old archive directory: %v", err)
+	}
+	if err := os.Rename(tempDir, a.getRootPath()); err != nil {
+		return fmt.Errorf("Failed to rename temporary directory to real name: %v", err)
+	}
+
+	return installFile(a.getFilePath(), a.Command.Body)
+}
+
+// prepare downloads and extracts the archive unless it is already installed.
+// The installation is locked so that only one process downloads the same version at once.
+func (a *Archive) prepare() error {
+	if a.isInstalled() {
+		lgr.Debug.Println("archive command already installed, skip installation.")
+		report.recordCacheHit()
+		return nil
+	}
+	if isOffline {
+		return fmt.Errorf("The archive command is not installed, it cannot be downloaded in offline mode")
+	}
+
+	lgr.Debug.Println("waiting for the lock of archive command.")
+	unlock, err := cache.Lock(a.Spec.Namespace, a.Spec.Name, a.Spec.Version)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// another process may have installed it while waiting for the lock
+	if a.isInstalled() {
+		lgr.Debug.Println("archive command installed by another process, skip installation.")
+		report.recordCacheHit()
+		return nil
+	}
+
+	lgr.Debug.Println("start downloading archive command.")
+	if err := a.download(); err != nil {
+		return err
+	}
+
+	lgr.Debug.Println("start installing archive command.")
+	return a.install()
+}
+
+// Run executes the entrypoint of the archive and returns output
+func (a *Archive) Run() error {
+	entrypoint, err := a.getEntrypointPath()
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	if err := a.prepare(); err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	lgr.Debug.Println("start verifying archive command.")
+	err = verifySignature(a.Spec.Archive.Signature, func() ([]byte, error) {
+		return ioutil.ReadFile(a.getFilePath())
+	})
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	archiveSpec := a.Spec
+	if err := cache.MarkUsed(archiveSpec.Namespace, archiveSpec.Name, archiveSpec.Version); err != nil {
+		lgr.Debug.Println(err)
+	}
+
+	lgr.Debug.Println("start executing archive command.")
+	lgr.Debug.Println("Namespace:", archiveSpec.Namespace, ",Name:", archiveSpec.Name, ",Version:", archiveSpec.Version, ",Entrypoint:", archiveSpec.Archive.Entrypoint)
+	err = execCommand(entrypoint, a.Args)
+	if err != nil {
+		lgr.Debug.Println(err)
+	} else {
+		lgr.Debug.Println("execute archive command succeeded.")
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// dummyTarGz returns a gzipped tarball which has the shell at the dummy entrypoint
+func dummyTarGz(t *testing.T, shell string) string {
+	dir, _ := ioutil.TempDir("", "sd-cmd_archive")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "bin"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, dummyEntrypoint), []byte(shell), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "data.txt"), []byte("data"), 0644)
+
+	buf := new(bytes.Buffer)
+	if err := util.CreateTarGz(dir, buf); err != nil {
+		t.Fatalf("failed to create tarball: %v", err)
+	}
+	return buf.String()
+}
+
+func TestNewArchive(t *testing.T) {
+	_, err := NewArchive(dummyCommandSpec(archiveFormat), []string{"arg1", "arg2"}, false)
+	assert.Nil(t, err)
+
+	// failure. no archive
+	spec := dummyCommandSpec(archiveFormat)
+	spec.Archive = nil
+	_, err = NewArchive(spec, []string{}, false)
+	assert.NotNil(t, err)
+
+	// failure. no entrypoint
+	spec = dummyCommandSpec(archiveFormat)
+	spec.Archive.Entrypoint = ""
+	_, err = NewArchive(spec, []string{}, false)
+	assert.NotNil(t, err)
+}
+
+func TestArchivePaths(t *testing.T) {
+	archive, _ := NewArchive(dummyCommandSpec(archiveFormat), []string{}, false)
+	dirPath := filepath.Join(config.BaseCommandPath, "foo-dummy/name-dummy/1.0.1")
+	assert.Equal(t, filepath.Join(dirPath, "sd-step.tar.gz"), archive.getFilePath())
+	assert.Equal(t, filepath.Join(dirPath, "archive"), archive.getRootPath())
+	entrypoint, err := archive.getEntrypointPath()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dirPath, "archive/bin/sd-step"), entrypoint)
+
+	// failure. the entrypoint is outside of the archive
+	archive.Spec.Archive.Entrypoint = "../../../../bin/sh"
+	_, err = archive.getEntrypointPath()
+	assert.NotNil(t, err)
+}
+
+func TestRunArchive(t *testing.T) {
+	body := dummyTarGz(t, validShell)
+	spec := dummyCommandSpec(archiveFormat)
+	spec.Archive.Sha256 = util.Sha256Sum([]byte(body))
+	archive, _ := NewArchive(spec, []string{"arg1"}, false)
+	defer os.RemoveAll(archive.getDirPath())
+
+	// failure. the downloaded archive does not match the digest
+	archive.Store = newDummyStore(dummyTarGz(t, invalidShell), spec, nil)
+	err := archive.Run()
+	assert.Contains(t, fmt.Sprint(err), "Checksum mismatch")
+	assert.False(t, archive.isInstalled())
+
+	// success
+	archive.Store = newDummyStore(body, spec, nil)
+	assert.Nil(t, archive.Run())
+	assert.True(t, archive.isInstalled())
+	data, err := ioutil.ReadFile(filepath.Join(archive.getRootPath(), "data.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "data", string(data))
+
+	// the installed archive is not downloaded again
+	archive.Store = newDummyStore("", spec, fmt.Errorf("store cause error"))
+	assert.Nil(t, archive.Run())
+
+	// the broken installed archive is extracted again
+	ioutil.WriteFile(archive.getFilePath(), []byte("broken"), 0644)
+	assert.False(t, archive.isInstalled())
+	archive.Store = newDummyStore(body, spec, nil)
+	assert.Nil(t, archive.Run())
+	assert.True(t, archive.isInstalled())
+
+	// failure. the entrypoint does not exist in the archive
+	archive.Spec.Archive.Entrypoint = "bin/notexist"
+	assert.NotNil(t, archive.Run())
+}
+
+func TestRunArchiveOffline(t *testing.T) {
+	defer func() { isOffline = false }()
+	isOffline = true
+
+	spec := dummyCommandSpec(archiveFormat)
+	archive, _ := NewArchive(spec, []string{}, false)
+	archive.Store = newDummyStore(dummyTarGz(t, validShell), spec, nil)
+	err := archive.Run()
+	assert.Contains(t, fmt.Sprint(err), "offline mode")
+}
+
+func TestRunArchiveTraversal(t *testing.T) {
+	spec := dummyCommandSpec(archiveFormat)
+	archive, _ := NewArchive(spec, []string{}, false)
+	defer os.RemoveAll(archive.getDirPath())
+
+	// failure. the archive has an entry outside of the destination
+	buf := new(bytes.Buffer)
+	dir, _ := ioutil.TempDir("", "sd-cmd_archive")
+	defer os.RemoveAll(dir)
+	os.Symlink("../../../evil", filepath.Join(dir, "evil"))
+	util.CreateTarGz(dir, buf)
+	archive.Store = newDummyStore(buf.String(), spec, nil)
+	err := archive.Run()
+	assert.Contains(t, fmt.Sprint(err), "Failed to extract archive")
+	assert.False(t, archive.isInstalled())
+}
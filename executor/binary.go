@@ -5,7 +5,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"time"
 
+	"github.com/screwdriver-cd/sd-cmd/cache"
 	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/store"
 	"github.com/screwdriver-cd/sd-cmd/util"
@@ -21,8 +24,28 @@ type Binary struct {
 	Command *store.Command
 }
 
-// NewBinary returns Binary object
+// platform which a variant of binary is selected for
+var (
+	platformOS   = runtime.GOOS
+	platformArch = runtime.GOARCH
+)
+
+// NewBinary returns Binary object.
+// If the binary has variants for multiple platforms, Spec has the variant for the running platform.
 func NewBinary(spec *util.CommandSpec, arg []string, isVerbose bool) (*Binary, error) {
+	if spec.Binary == nil {
+		return nil, fmt.Errorf("The binary is not specified")
+	}
+	variant, err := spec.Binary.SelectPlatform(platformOS, platformArch)
+	if err != nil {
+		return nil, err
+	}
+	if variant != spec.Binary {
+		selected := *spec
+		selected.Binary = variant
+		spec = &selected
+	}
+
 	storeapi := store.New(config.SDStoreURL, spec, config.SDToken)
 
 	storeapi.SetVerbose(isVerbose)
@@ -35,8 +58,14 @@ func NewBinary(spec *util.CommandSpec, arg []string, isVerbose bool) (*Binary, e
 	return binary, nil
 }
 
+// getBinDirPath returns the directory where the binary is installed.
+// A variant for a platform is installed in <os>-<arch> under the directory of the version.
 func (b *Binary) getBinDirPath() string {
-	return filepath.Join(config.BaseCommandPath, b.Spec.Namespace, b.Spec.Name, b.Spec.Version)
+	dirPath := cache.Dir(b.Spec.Namespace, b.Spec.Name, b.Spec.Version)
+	if b.Spec.Binary.OS != "" || b.Spec.Binary.Arch != "" {
+		return filepath.Join(dirPath, b.Spec.Binary.OS+"-"+b.Spec.Binary.Arch)
+	}
+	return dirPath
 }
 
 func (b *Binary) getBinFilePath() string {
@@ -52,73 +81,106 @@ func (b *Binary) isInstalled() bool {
 	if fInfo.Size() == 0 {
 		return false
 	}
+	if b.Spec.Binary.Sha256 != "" {
+		body, err := ioutil.ReadFile(b.getBinFilePath())
+		if err != nil {
+			return false
+		}
+		if err := verifyDigest(b.Spec.Binary.Sha256, body); err != nil {
+			lgr.Debug.Printf("installed binary command is broken: %v", err)
+			return false
+		}
+	}
 	return true
 }
 
 func (b *Binary) download() error {
+	start := time.Now()
 	cmd, err := b.Store.GetCommand()
 	if err != nil {
 		return err
 	}
+	report.recordDownload(start, len(cmd.Body))
 	b.Command = cmd
 	return nil
 }
 
 func (b *Binary) install() error {
-	binDirPath := b.getBinDirPath()
-	if err := os.MkdirAll(binDirPath, 0777); err != nil {
-		return fmt.Errorf("Failed to create command directory: %v", err)
+	if err := verifyDigest(b.Spec.Binary.Sha256, b.Command.Body); err != nil {
+		return fmt.Errorf("Failed to verify downloaded command: %v", err)
 	}
+	return installFile(b.getBinFilePath(), b.Command.Body)
+}
 
-	tempFile, err := ioutil.TempFile(binDirPath, "download")
-	if err != nil {
-		return fmt.Errorf("Failed to create command temporary file: %v", err)
+// installFile writes the file of the command so that nobody runs a half-written command
+func installFile(filePath string, body []byte) error {
+	if err := util.WriteFileAtomically(filePath, body, 0777); err != nil {
+		return fmt.Errorf("Failed to install command file: %v", err)
 	}
-	tempFileName := tempFile.Name()
-	// ignore error on file remove intentionally
-	defer os.Remove(tempFileName)
-	_, err = tempFile.Write(b.Command.Body)
-	closeError := tempFile.Close()
-	if err != nil {
-		return fmt.Errorf("Failed to write command file: %v", err)
+	return nil
+}
+
+// prepare downloads and installs the command unless it is already installed.
+// The installation is locked so that only one process downloads the same version at once.
+func (b *Binary) prepare() error {
+	if b.isInstalled() {
+		lgr.Debug.Println("binary command already installed, skip installation.")
+		report.recordCacheHit()
+		return nil
 	}
-	if closeError != nil {
-		return fmt.Errorf("Failed to close command file: %v", closeError)
+	if isOffline {
+		return fmt.Errorf("The binary command is not installed, it cannot be downloaded in offline mode")
 	}
-	if err := os.Chmod(tempFileName, 0777); err != nil {
-		return fmt.Errorf("Failed to change the access permissions of command file: %v", err)
+
+	lgr.Debug.Println("waiting for the lock of binary command.")
+	unlock, err := cache.Lock(b.Spec.Namespace, b.Spec.Name, b.Spec.Version)
+	if err != nil {
+		return err
 	}
-	if err := os.Rename(tempFileName, b.getBinFilePath()); err != nil {
-		return fmt.Errorf("Failed to rename temporary file to real name: %v", err)
+	defer unlock()
+
+	// another process may have installed it while waiting for the lock
+	if b.isInstalled() {
+		lgr.Debug.Println("binary command installed by another process, skip installation.")
+		report.recordCacheHit()
+		return nil
 	}
-	return nil
+
+	lgr.Debug.Println("start downloading binary command.")
+	err = b.download()
+	if err != nil {
+		return err
+	}
+
+	lgr.Debug.Println("start installing binary command.")
+	return b.install()
 }
 
 // Run executes command and returns output
 func (b *Binary) Run() error {
-	if b.isInstalled() {
-		lgr.Debug.Println("binary command already installed, skip installation.")
-	} else {
-		lgr.Debug.Println("start downloading binary command.")
-
-		err := b.download()
-		if err != nil {
-			lgr.Debug.Println(err)
-			return err
-		}
+	err := b.prepare()
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
 
-		lgr.Debug.Println("start installing binary command.")
-		err = b.install()
-		if err != nil {
-			lgr.Debug.Println(err)
-			return err
-		}
+	lgr.Debug.Println("start verifying binary command.")
+	err = verifySignature(b.Spec.Binary.Signature, func() ([]byte, error) {
+		return ioutil.ReadFile(b.getBinFilePath())
+	})
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
 	}
 
 	binarySpec := b.Spec
+	if err := cache.MarkUsed(binarySpec.Namespace, binarySpec.Name, binarySpec.Version); err != nil {
+		lgr.Debug.Println(err)
+	}
+
 	lgr.Debug.Println("start executing binary command.")
 	lgr.Debug.Println("Namespace:", binarySpec.Namespace, ",Name:", binarySpec.Name, ",Version:", binarySpec.Version)
-	err := execCommand(b.getBinFilePath(), b.Args)
+	err = execCommand(b.getBinFilePath(), b.Args)
 	if err != nil {
 		lgr.Debug.Println(err)
 	} else {
@@ -2,15 +2,22 @@ package executor
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/screwdriver-cd/sd-cmd/cache"
 	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/store"
+	"github.com/screwdriver-cd/sd-cmd/util"
 )
 
 func TestNewBinary(t *testing.T) {
@@ -18,6 +25,42 @@ func TestNewBinary(t *testing.T) {
 	if err != nil {
 		t.Errorf("err=%q, want nil", err)
 	}
+
+	// failure. no binary
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary = nil
+	_, err = NewBinary(spec, []string{}, false)
+	assert.NotNil(t, err)
+}
+
+func TestNewBinaryWithPlatforms(t *testing.T) {
+	defer func(goos, goarch string) {
+		platformOS, platformArch = goos, goarch
+	}(platformOS, platformArch)
+
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary = &util.Binary{
+		Platforms: []*util.Binary{
+			{OS: "linux", Arch: "amd64", File: "sd-step-amd64", Sha256: "amd64-digest"},
+			{OS: "linux", Arch: "arm64", File: "sd-step-arm64", Sha256: "arm64-digest"},
+		},
+	}
+
+	// success. the variant for the running platform is selected
+	platformOS, platformArch = "linux", "arm64"
+	bin, err := NewBinary(spec, []string{}, false)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "sd-step-arm64", bin.Spec.Binary.File)
+		assert.Equal(t, "arm64-digest", bin.Spec.Binary.Sha256)
+		assert.Equal(t, filepath.Join(config.BaseCommandPath, "foo-dummy/name-dummy/1.0.1/linux-arm64/sd-step-arm64"), bin.getBinFilePath())
+		// the original spec is not changed
+		assert.Len(t, spec.Binary.Platforms, 2)
+	}
+
+	// failure. no variant for the running platform
+	platformOS, platformArch = "darwin", "arm64"
+	_, err = NewBinary(spec, []string{}, false)
+	assert.EqualError(t, err, "There is no binary for darwin/arm64, available platforms are linux/amd64, linux/arm64")
 }
 
 func TestGetBinDirPath(t *testing.T) {
@@ -173,3 +216,165 @@ func TestRunParallel(t *testing.T) {
 	wg.Wait()
 	os.Remove(binPath)
 }
+
+// countingStore is a dummy store which counts the downloads
+type countingStore struct {
+	dummyStore
+	downloads *int32
+}
+
+func (c *countingStore) GetCommand() (*store.Command, error) {
+	atomic.AddInt32(c.downloads, 1)
+	return c.dummyStore.GetCommand()
+}
+
+func TestRunParallelInstallsOnce(t *testing.T) {
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.File = "parallel"
+	binPath := filepath.Join(config.BaseCommandPath, spec.Namespace, spec.Name, spec.Version, spec.Binary.File)
+	defer os.Remove(binPath)
+
+	// the installers wait for the one holding the install lock, and do not download again
+	var downloads int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		bin, _ := NewBinary(spec, []string{}, false)
+		bin.Store = &countingStore{
+			dummyStore: dummyStore{body: []byte(validShell), spec: spec, getCommandDelay: 200 * time.Millisecond},
+			downloads:  &downloads,
+		}
+		wg.Add(1)
+		go func(bin *Binary) {
+			defer wg.Done()
+			if err := bin.prepare(); err != nil {
+				t.Errorf("err=%q, want nil", err)
+			}
+		}(bin)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), downloads)
+}
+
+func TestRunWithChecksum(t *testing.T) {
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.File = "checksum"
+	binPath := filepath.Join(config.BaseCommandPath, spec.Namespace, spec.Name, spec.Version, spec.Binary.File)
+	defer os.Remove(binPath)
+
+	// failure. the downloaded command does not match the digest
+	spec.Binary.Sha256 = util.Sha256Sum([]byte(invalidShell))
+	bin, _ := NewBinary(spec, []string{}, false)
+	bin.Store = newDummyStore(validShell, spec, nil)
+	err := bin.Run()
+	assert.Contains(t, fmt.Sprint(err), "Checksum mismatch")
+	assert.False(t, bin.isInstalled())
+	_, err = os.Stat(binPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// success
+	spec.Binary.Sha256 = util.Sha256Sum([]byte(validShell))
+	bin, _ = NewBinary(spec, []string{}, false)
+	bin.Store = newDummyStore(validShell, spec, nil)
+	assert.Nil(t, bin.Run())
+	assert.True(t, bin.isInstalled())
+
+	// the broken installed command is downloaded again
+	ioutil.WriteFile(binPath, []byte("broken"), 0777)
+	assert.False(t, bin.isInstalled())
+	assert.Nil(t, bin.Run())
+	assert.True(t, bin.isInstalled())
+}
+
+func TestRunMarksUsed(t *testing.T) {
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.File = "marks_used"
+	bin, _ := NewBinary(spec, []string{}, false)
+	bin.Store = newDummyStore(validShell, spec, nil)
+	defer os.RemoveAll(bin.getBinDirPath())
+
+	before := time.Now().Add(-time.Second)
+	assert.Nil(t, bin.Run())
+
+	entries, err := cache.List()
+	assert.Nil(t, err)
+	for _, entry := range entries {
+		if entry.Path == bin.getBinDirPath() {
+			assert.True(t, entry.LastUsed.After(before))
+			return
+		}
+	}
+	t.Errorf("%v is not listed in cache", bin.getBinDirPath())
+}
+
+// downloadLogStore is a dummy store which records each download to a file shared across processes
+type downloadLogStore struct {
+	dummyStore
+	logPath string
+}
+
+func (d *downloadLogStore) GetCommand() (*store.Command, error) {
+	file, err := os.OpenFile(d.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(file, os.Getpid())
+	file.Close()
+	return d.dummyStore.GetCommand()
+}
+
+func TestConcurrentInstall(t *testing.T) {
+	baseCommandPath, _ := ioutil.TempDir("", "sd-cmd_concurrent")
+	defer os.RemoveAll(baseCommandPath)
+	downloadLog := filepath.Join(baseCommandPath, "download.log")
+
+	// start installers in other processes at once
+	installerCount := 4
+	installers := make([]*exec.Cmd, installerCount)
+	for i := range installers {
+		installers[i] = exec.Command(os.Args[0], "-test.run=TestInstallHelperProcess")
+		installers[i].Env = []string{
+			"GO_WANT_HELPER_PROCESS=1",
+			"SD_BASE_COMMAND_PATH=" + baseCommandPath,
+			"DOWNLOAD_LOG=" + downloadLog,
+		}
+		installers[i].Stderr = os.Stderr
+		if err := installers[i].Start(); err != nil {
+			t.Fatalf("failed to start installer: %v", err)
+		}
+	}
+	for _, installer := range installers {
+		assert.Nil(t, installer.Wait())
+	}
+
+	// only one installer downloads the command
+	downloads, _ := ioutil.ReadFile(downloadLog)
+	assert.Len(t, strings.Fields(string(downloads)), 1)
+
+	installed, _ := ioutil.ReadFile(filepath.Join(baseCommandPath, dummyNameSpace, dummyName, dummyVersion, "concurrent"))
+	assert.Equal(t, validShell, string(installed))
+}
+
+func TestInstallHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	config.BaseCommandPath = os.Getenv("SD_BASE_COMMAND_PATH")
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.File = "concurrent"
+	bin, _ := NewBinary(spec, []string{}, false)
+	bin.Store = &downloadLogStore{
+		dummyStore: dummyStore{
+			body:            []byte(validShell),
+			spec:            spec,
+			getCommandDelay: 200 * time.Millisecond,
+		},
+		logPath: os.Getenv("DOWNLOAD_LOG"),
+	}
+
+	if err := bin.prepare(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
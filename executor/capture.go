@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// captureWriter writes the output of the command to a file up to maxSize bytes.
+// It never returns an error so that the output keeps streaming to the console via io.MultiWriter.
+type captureWriter struct {
+	file       *os.File
+	remaining  int64
+	maxSize    int64
+	timestamps bool
+	// lineStart is true if the next byte starts a new line
+	lineStart bool
+	truncated bool
+	// now returns the time of the timestamp
+	now func() time.Time
+}
+
+func newCaptureWriter(path string, maxSize int64, timestamps bool) (*captureWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create output capture file: %v", err)
+	}
+	return &captureWriter{
+		file:       file,
+		remaining:  maxSize,
+		maxSize:    maxSize,
+		timestamps: timestamps,
+		lineStart:  true,
+		now:        time.Now,
+	}, nil
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if !w.timestamps {
+		w.writeCapped(p)
+		return len(p), nil
+	}
+
+	for rest := p; len(rest) > 0; {
+		if w.lineStart {
+			w.writeCapped([]byte(w.now().UTC().Format(time.RFC3339Nano) + " "))
+			w.lineStart = false
+		}
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+			w.lineStart = true
+		}
+		w.writeCapped(line)
+		rest = rest[len(line):]
+	}
+	return len(p), nil
+}
+
+// writeCapped writes p unless the file reaches maxSize, and then notes the truncation once
+func (w *captureWriter) writeCapped(p []byte) {
+	if w.truncated {
+		return
+	}
+	if int64(len(p)) > w.remaining {
+		p = p[:w.remaining]
+		w.truncated = true
+	}
+	if _, err := w.file.Write(p); err != nil {
+		lgr.Debug.Printf("failed to capture output: %v", err)
+	}
+	w.remaining -= int64(len(p))
+	if w.truncated {
+		fmt.Fprintf(w.file, "\n[sd-cmd] the output is truncated at %d bytes\n", w.maxSize)
+	}
+}
+
+func (w *captureWriter) Close() error {
+	return w.file.Close()
+}
+
+// outputCapture is the files which stdout and stderr of the command are captured into
+type outputCapture struct {
+	stdout *captureWriter
+	stderr *captureWriter
+}
+
+// capture is the output capture of the current execution. It is nil unless capturing is enabled.
+var capture *outputCapture
+
+// prepareCapture creates the files to capture the output of the command into SD_ARTIFACTS_DIR/.sd/commands.
+// They are named like the execution report, with .stdout.log and .stderr.log suffixes.
+func prepareCapture(smallSpec *util.CommandSpec) error {
+	capture = nil
+	if !(isCaptureOutput || config.CaptureOutput) {
+		return nil
+	}
+
+	dirPath := filepath.Join(config.SDArtifactsDir, ".sd", "commands")
+	if err := os.MkdirAll(dirPath, 0777); err != nil {
+		return fmt.Errorf("Failed to create output capture directory: %v", err)
+	}
+	prefix := filepath.Join(dirPath, fmt.Sprintf("%v-%v-%v-%v", time.Now().Unix(), os.Getpid(), smallSpec.Namespace, smallSpec.Name))
+	timestamps := isCaptureTimestamps || config.CaptureTimestamps
+
+	stdout, err := newCaptureWriter(prefix+".stdout.log", config.CaptureMaxSize, timestamps)
+	if err != nil {
+		return err
+	}
+	stderr, err := newCaptureWriter(prefix+".stderr.log", config.CaptureMaxSize, timestamps)
+	if err != nil {
+		stdout.Close()
+		return err
+	}
+	capture = &outputCapture{stdout: stdout, stderr: stderr}
+	return nil
+}
+
+// writers returns the writers of stdout and stderr of the command which stream to the console
+func (c *outputCapture) writers() (io.Writer, io.Writer) {
+	if c == nil {
+		return os.Stdout, os.Stderr
+	}
+	return io.MultiWriter(os.Stdout, c.stdout), io.MultiWriter(os.Stderr, c.stderr)
+}
+
+func (c *outputCapture) close() {
+	if c == nil {
+		return
+	}
+	c.stdout.Close()
+	c.stderr.Close()
+}
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+func newTestCaptureWriter(t *testing.T, maxSize int64, timestamps bool) (*captureWriter, string) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_capture")
+	path := filepath.Join(dir, "out.log")
+	w, err := newCaptureWriter(path, maxSize, timestamps)
+	if err != nil {
+		t.Fatalf("failed to create capture writer: %v", err)
+	}
+	w.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return w, path
+}
+
+func TestCaptureWriter(t *testing.T) {
+	// without timestamps
+	w, path := newTestCaptureWriter(t, 1024, false)
+	defer os.RemoveAll(filepath.Dir(path))
+	n, err := w.Write([]byte("hello\nworld"))
+	assert.Equal(t, 11, n)
+	assert.Nil(t, err)
+	w.Close()
+	data, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "hello\nworld", string(data))
+
+	// with timestamps. a line written in pieces has one timestamp
+	w, path = newTestCaptureWriter(t, 1024, true)
+	defer os.RemoveAll(filepath.Dir(path))
+	w.Write([]byte("hello\nwor"))
+	w.Write([]byte("ld\n"))
+	w.Write([]byte("\n"))
+	w.Close()
+	data, _ = ioutil.ReadFile(path)
+	assert.Equal(t, "2024-01-02T03:04:05Z hello\n2024-01-02T03:04:05Z world\n2024-01-02T03:04:05Z \n", string(data))
+
+	// the output over the size is truncated, but all is reported as written
+	w, path = newTestCaptureWriter(t, 8, false)
+	defer os.RemoveAll(filepath.Dir(path))
+	n, err = w.Write([]byte("0123456"))
+	assert.Equal(t, 7, n)
+	assert.Nil(t, err)
+	n, err = w.Write([]byte("789"))
+	assert.Equal(t, 3, n)
+	assert.Nil(t, err)
+	w.Write([]byte("more"))
+	w.Close()
+	data, _ = ioutil.ReadFile(path)
+	assert.Equal(t, "01234567\n[sd-cmd] the output is truncated at 8 bytes\n", string(data))
+}
+
+func TestExecCommandCapture(t *testing.T) {
+	artifactsDir := config.SDArtifactsDir
+	config.SDArtifactsDir, _ = ioutil.TempDir("", "sd-cmd_capture")
+	defer func() {
+		os.RemoveAll(config.SDArtifactsDir)
+		config.SDArtifactsDir = artifactsDir
+		isCaptureOutput = false
+		capture = nil
+	}()
+
+	// not captured unless enabled
+	assert.Nil(t, prepareCapture(dummyCommandSpec(binaryFormat)))
+	assert.Nil(t, capture)
+
+	isCaptureOutput = true
+	assert.Nil(t, prepareCapture(dummyCommandSpec(binaryFormat)))
+	assert.Nil(t, execCommand("/bin/sh", []string{"-c", "echo out; echo err >&2"}))
+	capture.close()
+
+	for suffix, expected := range map[string]string{".stdout.log": "out\n", ".stderr.log": "err\n"} {
+		paths, _ := filepath.Glob(filepath.Join(config.SDArtifactsDir, ".sd", "commands", fmt.Sprintf("*-%v-foo-dummy-name-dummy%s", os.Getpid(), suffix)))
+		if assert.Len(t, paths, 1, suffix) {
+			data, _ := ioutil.ReadFile(paths[0])
+			assert.Equal(t, expected, string(data), suffix)
+		}
+	}
+}
+
+func TestNewWithCapture(t *testing.T) {
+	defer func() {
+		isCaptureOutput = false
+		isCaptureTimestamps = false
+		capture.close()
+		capture = nil
+	}()
+	defer os.RemoveAll(filepath.Join(config.SDArtifactsDir, ".sd"))
+
+	sdapi := newDummySDAPI(dummyCommandSpec(binaryFormat), nil)
+	_, err := New(sdapi, []string{"--capture-output", "--capture-timestamps", "ns/cmd@ver"})
+	assert.Nil(t, err)
+	if assert.NotNil(t, capture) {
+		assert.True(t, capture.stdout.timestamps)
+		assert.Equal(t, config.CaptureMaxSize, capture.stderr.maxSize)
+	}
+}
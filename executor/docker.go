@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const dockerPath = "docker"
+
+// Docker is the Docker Executor struct
+type Docker struct {
+	Args []string
+	Spec *util.CommandSpec
+}
+
+// NewDocker returns the Docker struct
+func NewDocker(spec *util.CommandSpec, args []string, isVerbose bool) (docker *Docker, err error) {
+	if spec.Docker == nil || spec.Docker.Image == "" {
+		return nil, fmt.Errorf("The docker image is not specified")
+	}
+
+	docker = &Docker{
+		Args: args,
+		Spec: spec,
+	}
+	return docker, nil
+}
+
+// runArgs returns arguments of "docker run".
+// The workspace and SD_ARTIFACTS_DIR are mounted on the same paths in the container.
+// The environment variables declared in the spec are passed to the container.
+func (d *Docker) runArgs() ([]string, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get working directory: %v", err)
+	}
+
+	runArgs := []string{"run", "--rm"}
+	if isOffline {
+		runArgs = append(runArgs, "--pull", "never")
+	}
+	if !terminal.IsTerminal(syscall.Stdin) {
+		runArgs = append(runArgs, "-i")
+	}
+	runArgs = append(runArgs, "-v", workDir+":"+workDir, "-w", workDir)
+	passed := make(map[string]bool)
+	if config.SDArtifactsDir != "" {
+		runArgs = append(runArgs,
+			"-v", config.SDArtifactsDir+":"+config.SDArtifactsDir,
+			"-e", "SD_ARTIFACTS_DIR="+config.SDArtifactsDir)
+		passed["SD_ARTIFACTS_DIR"] = true
+	}
+
+	// pass the declared variables by name so that their values do not appear in the arguments
+	for _, envVar := range d.Spec.Env {
+		if passed[envVar.Name] {
+			continue
+		}
+		runArgs = append(runArgs, "-e", envVar.Name)
+		passed[envVar.Name] = true
+	}
+
+	runArgs = append(runArgs, d.Spec.Docker.Image)
+	if d.Spec.Docker.Command != "" {
+		runArgs = append(runArgs, d.Spec.Docker.Command)
+	}
+	return append(runArgs, d.Args...), nil
+}
+
+// Run executes "docker run" with an image, command and args from a CLI
+func (d *Docker) Run() (err error) {
+	if err = verifyUnsignable(d.Spec.Format); err != nil {
+		return
+	}
+
+	runArgs, err := d.runArgs()
+	if err != nil {
+		lgr.Debug.Println(err)
+		return
+	}
+
+	dockerSpec := d.Spec
+	lgr.Debug.Println("start executing docker command.")
+	lgr.Debug.Println("Namespace:", dockerSpec.Namespace, ",Name:", dockerSpec.Name, ",Version:", dockerSpec.Version)
+	lgr.Debug.Println("Image:", dockerSpec.Docker.Image, ",Command:", dockerSpec.Docker.Command)
+	err = execCommand(dockerPath, runArgs)
+	if err != nil {
+		lgr.Debug.Println(err)
+	} else {
+		lgr.Debug.Println("execute docker command succeeded.")
+	}
+	return
+}
@@ -0,0 +1,163 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// dockerExitCode is the exit code the fake container runtime returns
+var dockerExitCode = 0
+
+func fakeDockerCommand(name string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestDockerHelperProcess", "--", name}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{
+		"GO_WANT_HELPER_PROCESS=1",
+		"DOCKER_EXIT_CODE=" + strconv.Itoa(dockerExitCode),
+	}
+	return cmd
+}
+
+func TestNewDocker(t *testing.T) {
+	_, err := NewDocker(dummyCommandSpec(dockerFormat), dummyArgs, false)
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	}
+
+	// failure. the image is not specified
+	spec := dummyCommandSpec(dockerFormat)
+	spec.Docker.Image = ""
+	_, err = NewDocker(spec, dummyArgs, false)
+	assert.NotNil(t, err)
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	workDir, _ := os.Getwd()
+	spec := dummyCommandSpec(dockerFormat)
+	docker, _ := NewDocker(spec, dummyArgs, false)
+	args, err := docker.runArgs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"run", "--rm"}, args[:2])
+	assert.Contains(t, args, workDir+":"+workDir)
+	assert.Contains(t, args, config.SDArtifactsDir+":"+config.SDArtifactsDir)
+	assert.Contains(t, args, "SD_ARTIFACTS_DIR="+config.SDArtifactsDir)
+	assert.Equal(t, append([]string{dummyImage, dummyCommand}, dummyArgs...), args[len(args)-4:])
+
+	// never pull the image in offline mode
+	isOffline = true
+	args, _ = docker.runArgs()
+	isOffline = false
+	assert.Equal(t, []string{"run", "--rm", "--pull", "never"}, args[:4])
+
+	// the command is omitted
+	spec.Docker.Command = ""
+	docker, _ = NewDocker(spec, dummyArgs, false)
+	args, _ = docker.runArgs()
+	assert.Equal(t, append([]string{dummyImage}, dummyArgs...), args[len(args)-3:])
+
+	// the declared environment variables are passed by name
+	spec.Env = []*util.EnvVar{{Name: "API_TOKEN", Required: true}}
+	docker, _ = NewDocker(spec, dummyArgs, false)
+	args, _ = docker.runArgs()
+	assert.Equal(t, append([]string{"-e", "API_TOKEN", dummyImage}, dummyArgs...), args[len(args)-5:])
+
+	// each variable is passed once
+	spec.Env = append(spec.Env, &util.EnvVar{Name: "API_TOKEN"}, &util.EnvVar{Name: "SD_ARTIFACTS_DIR"})
+	docker, _ = NewDocker(spec, dummyArgs, false)
+	args, _ = docker.runArgs()
+	count := 0
+	for _, arg := range args {
+		if arg == "API_TOKEN" || strings.HasPrefix(arg, "SD_ARTIFACTS_DIR") {
+			count++
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestRunDocker(t *testing.T) {
+	logBuffer.Reset()
+	command = fakeDockerCommand
+	defer func() {
+		command = exec.Command
+		dockerExitCode = 0
+	}()
+
+	// success
+	docker, _ := NewDocker(dummyCommandSpec(dockerFormat), dummyArgs, false)
+	err := docker.Run()
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	}
+
+	// failure. the exit code of the container is passed through
+	dockerExitCode = 3
+	err = docker.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if assert.True(t, ok) {
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+
+	// failure. refused by the enforce policy without running docker
+	defer func(policy string) { config.SignaturePolicy = policy }(config.SignaturePolicy)
+	config.SignaturePolicy = config.SignaturePolicyEnforce
+	dockerExitCode = 0
+	logBuffer.Reset()
+	err = docker.Run()
+	assert.EqualError(t, err, "A docker command can not be signed, so it is refused by SD_CMD_SIGNATURE_POLICY=enforce")
+	assert.NotContains(t, logBuffer.String(), "start executing docker command")
+}
+
+func TestDockerHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 {
+		if args[0] == "--" {
+			args = args[1:]
+			break
+		}
+		args = args[1:]
+	}
+	if len(args) < 3 {
+		fmt.Fprintf(os.Stderr, "no command\n")
+		os.Exit(2)
+	}
+
+	cmd, subcmd, args := args[0], args[1], args[2:]
+	if cmd != dockerPath {
+		fmt.Fprintf(os.Stderr, "expected '%v', but %v\n", dockerPath, cmd)
+		os.Exit(1)
+	}
+	if subcmd != "run" {
+		fmt.Fprintf(os.Stderr, "expected 'run', but %v\n", subcmd)
+		os.Exit(1)
+	}
+
+	expected := append([]string{dummyImage, dummyCommand}, dummyArgs...)
+	if len(args) < len(expected) {
+		fmt.Fprintf(os.Stderr, "docker run args are too short: %v\n", args)
+		os.Exit(1)
+	}
+	args = args[len(args)-len(expected):]
+	for i := range expected {
+		if args[i] != expected[i] {
+			fmt.Fprintf(os.Stderr, "docker run args is expected %v, but %v\n", expected[i], args[i])
+			os.Exit(1)
+		}
+	}
+
+	code, _ := strconv.Atoi(os.Getenv("DOCKER_EXIT_CODE"))
+	os.Exit(code)
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// defaultEnvAllowlist is names of environment variables passed to the command even if the environment is scrubbed
+var defaultEnvAllowlist = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "TMPDIR", "TZ", "SD_ARTIFACTS_DIR",
+}
+
+// commandEnviron is the environment of the command. nil means the environment of sd-cmd as it is.
+var commandEnviron []string
+
+// commandEnv returns the environment of the command with the defaults of the declared variables applied.
+// If clean is true, it contains the declared variables and the allowlist only, each of them once.
+// It fails with all the missing variables if some required variables are not set.
+func commandEnv(spec *util.CommandSpec, clean bool) ([]string, error) {
+	if len(spec.Env) == 0 && !clean {
+		return nil, nil
+	}
+
+	var environ []string
+	added := make(map[string]bool)
+	if clean {
+		allowlist := append(append([]string{}, defaultEnvAllowlist...), config.EnvAllowlist...)
+		for _, name := range allowlist {
+			if value, ok := os.LookupEnv(name); ok && !added[name] {
+				environ = append(environ, name+"="+value)
+				added[name] = true
+			}
+		}
+	} else {
+		environ = os.Environ()
+	}
+
+	var missing []string
+	for _, envVar := range spec.Env {
+		value, ok := os.LookupEnv(envVar.Name)
+		switch {
+		case added[envVar.Name]:
+			// already passed by the allowlist or by the same declaration
+		case ok:
+			if clean {
+				environ = append(environ, envVar.Name+"="+value)
+				added[envVar.Name] = true
+			}
+		case envVar.Default != "":
+			environ = append(environ, envVar.Name+"="+envVar.Default)
+			added[envVar.Name] = true
+		case envVar.Required:
+			if envVar.Description != "" {
+				missing = append(missing, fmt.Sprintf("%s (%s)", envVar.Name, envVar.Description))
+			} else {
+				missing = append(missing, envVar.Name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("The required environment variables are not set: %s", strings.Join(missing, ", "))
+	}
+	return environ, nil
+}
@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+func TestCommandEnv(t *testing.T) {
+	defer func(allowlist []string) { config.EnvAllowlist = allowlist }(config.EnvAllowlist)
+	os.Setenv("SD_CMD_TEST_TOKEN", "secret")
+	os.Setenv("SD_CMD_TEST_UNDECLARED", "leak")
+	defer os.Unsetenv("SD_CMD_TEST_TOKEN")
+	defer os.Unsetenv("SD_CMD_TEST_UNDECLARED")
+
+	// the environment of sd-cmd is used as it is without declarations
+	spec := dummyCommandSpec(binaryFormat)
+	environ, err := commandEnv(spec, false)
+	assert.Nil(t, err)
+	assert.Nil(t, environ)
+
+	// the default is applied to the unset variable
+	spec.Env = []*util.EnvVar{
+		{Name: "SD_CMD_TEST_TOKEN", Required: true},
+		{Name: "SD_CMD_TEST_CONFIG", Default: "/etc/test.conf"},
+		{Name: "SD_CMD_TEST_OPTIONAL"},
+	}
+	environ, err = commandEnv(spec, false)
+	assert.Nil(t, err)
+	assert.Contains(t, environ, "SD_CMD_TEST_TOKEN=secret")
+	assert.Contains(t, environ, "SD_CMD_TEST_CONFIG=/etc/test.conf")
+	assert.Contains(t, environ, "SD_CMD_TEST_UNDECLARED=leak")
+	assert.Contains(t, environ, "PATH="+os.Getenv("PATH"))
+
+	// the set variable takes precedence over the default
+	os.Setenv("SD_CMD_TEST_CONFIG", "/tmp/test.conf")
+	environ, err = commandEnv(spec, false)
+	os.Unsetenv("SD_CMD_TEST_CONFIG")
+	assert.Nil(t, err)
+	assert.Contains(t, environ, "SD_CMD_TEST_CONFIG=/tmp/test.conf")
+	assert.NotContains(t, environ, "SD_CMD_TEST_CONFIG=/etc/test.conf")
+
+	// the scrubbed environment has the declared variables and the allowlist only
+	os.Setenv("SD_CMD_TEST_ALLOWED", "allowed")
+	defer os.Unsetenv("SD_CMD_TEST_ALLOWED")
+	config.EnvAllowlist = []string{"SD_CMD_TEST_ALLOWED"}
+	environ, err = commandEnv(spec, true)
+	assert.Nil(t, err)
+	assert.Contains(t, environ, "SD_CMD_TEST_TOKEN=secret")
+	assert.Contains(t, environ, "SD_CMD_TEST_CONFIG=/etc/test.conf")
+	assert.Contains(t, environ, "SD_CMD_TEST_ALLOWED=allowed")
+	assert.Contains(t, environ, "PATH="+os.Getenv("PATH"))
+	assert.NotContains(t, environ, "SD_CMD_TEST_UNDECLARED=leak")
+	for _, env := range environ {
+		assert.False(t, strings.HasPrefix(env, "SD_CMD_TEST_OPTIONAL="), env)
+	}
+
+	// the variable which is declared and allowed is added once
+	config.EnvAllowlist = []string{"SD_CMD_TEST_TOKEN", "PATH"}
+	spec.Env = append(spec.Env, &util.EnvVar{Name: "SD_CMD_TEST_CONFIG", Default: "/etc/another.conf"})
+	environ, err = commandEnv(spec, true)
+	assert.Nil(t, err)
+	names := make(map[string]int)
+	for _, env := range environ {
+		names[strings.SplitN(env, "=", 2)[0]]++
+	}
+	assert.Equal(t, 1, names["SD_CMD_TEST_TOKEN"])
+	assert.Equal(t, 1, names["SD_CMD_TEST_CONFIG"])
+	assert.Equal(t, 1, names["PATH"])
+	assert.Contains(t, environ, "SD_CMD_TEST_CONFIG=/etc/test.conf")
+
+	// failure. all the missing required variables are reported
+	spec.Env = []*util.EnvVar{
+		{Name: "SD_CMD_TEST_MISSING", Description: "token of the test service", Required: true},
+		{Name: "SD_CMD_TEST_TOKEN", Required: true},
+		{Name: "SD_CMD_TEST_ANOTHER", Required: true},
+	}
+	_, err = commandEnv(spec, false)
+	assert.EqualError(t, err, "The required environment variables are not set: SD_CMD_TEST_MISSING (token of the test service), SD_CMD_TEST_ANOTHER")
+}
+
+func TestNewWithEnv(t *testing.T) {
+	defer func() {
+		isCleanEnv = false
+		commandEnviron = nil
+	}()
+	os.Setenv("SD_CMD_TEST_UNDECLARED", "leak")
+	defer os.Unsetenv("SD_CMD_TEST_UNDECLARED")
+
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Env = []*util.EnvVar{{Name: "SD_CMD_TEST_MISSING", Required: true}}
+	_, err := New(newDummySDAPI(spec, nil), []string{"ns/cmd@ver"})
+	assert.EqualError(t, err, "The required environment variables are not set: SD_CMD_TEST_MISSING")
+
+	spec.Env = []*util.EnvVar{{Name: "SD_CMD_TEST_MISSING", Default: "default"}}
+	_, err = New(newDummySDAPI(spec, nil), []string{"--clean-env", "ns/cmd@ver"})
+	assert.Nil(t, err)
+	assert.Contains(t, commandEnviron, "SD_CMD_TEST_MISSING=default")
+	assert.NotContains(t, commandEnviron, "SD_CMD_TEST_UNDECLARED=leak")
+
+	// the docker client keeps its environment
+	dockerSpec := dummyCommandSpec(dockerFormat)
+	dockerSpec.Env = spec.Env
+	_, err = New(newDummySDAPI(dockerSpec, nil), []string{"--clean-env", "ns/cmd@ver"})
+	assert.Nil(t, err)
+	assert.Contains(t, commandEnviron, "SD_CMD_TEST_UNDECLARED=leak")
+}
+
+func TestExecCommandEnv(t *testing.T) {
+	defer func() { commandEnviron = nil }()
+	// command may be replaced by a fake in other tests
+	defer func(c func(string, ...string) *exec.Cmd) { command = c }(command)
+	command = exec.Command
+
+	commandEnviron = []string{"SD_CMD_TEST_TOKEN=secret"}
+	assert.Nil(t, execCommand("/bin/sh", []string{"-c", `test "$SD_CMD_TEST_TOKEN" = secret && test -z "$HOME"`}))
+}
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/pkg/errors"
+	"github.com/screwdriver-cd/sd-cmd/cache"
 	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/logger"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
@@ -28,6 +29,21 @@ var (
 var (
 	isDebug   = false
 	isVerbose = false
+	isOffline = false
+	// isExplainResolution prints how the version of the command is resolved
+	isExplainResolution = false
+	// isFrozen fails if the command is not pinned by the lockfile
+	isFrozen = false
+	// isReport writes the execution report
+	isReport = false
+	// isCaptureOutput captures stdout and stderr of the command into files
+	isCaptureOutput = false
+	// isCaptureTimestamps prefixes each line of the captured output with a timestamp
+	isCaptureTimestamps = false
+	// isCleanEnv runs the command with the declared environment variables and the allowlist only
+	isCleanEnv = false
+	// execTimeout is the timeout of executing the command. 0 means no timeout
+	execTimeout time.Duration
 )
 
 // Executor is a Executor endpoint
@@ -54,6 +70,14 @@ func parseExecSubCommands(args []string) ([]string, error) {
 	f := flag.NewFlagSet("exec", flag.ContinueOnError)
 	f.BoolVar(&isDebug, "debug", false, "output log to file")
 	f.BoolVar(&isVerbose, "v", false, "output verbose log to console")
+	f.BoolVar(&isOffline, "offline", false, "execute cached command without network")
+	f.BoolVar(&isExplainResolution, "explain-resolution", false, "print candidate versions and chosen version")
+	f.BoolVar(&isFrozen, "frozen", false, "fail if the command is not pinned by the lockfile")
+	f.BoolVar(&isReport, "report", false, "write the execution report as JSON to SD_ARTIFACTS_DIR")
+	f.BoolVar(&isCaptureOutput, "capture-output", false, "capture stdout and stderr of the command into files in SD_ARTIFACTS_DIR")
+	f.BoolVar(&isCaptureTimestamps, "capture-timestamps", false, "prefix each line of the captured output with a timestamp")
+	f.BoolVar(&isCleanEnv, "clean-env", false, "run the command with the declared environment variables and the allowlist only")
+	f.DurationVar(&execTimeout, "timeout", 0, "terminate the command if it does not finish within the duration (e.g. 10m)")
 	err := f.Parse(args)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse exec args: %w", err)
@@ -78,12 +102,51 @@ func createLogFile(dirPath, filename string) (io.WriteCloser, error) {
 	return file, nil
 }
 
+// getSpec returns the command spec from Screwdriver API and persists it for offline mode.
+// A version range is resolved with the cached version list first.
+// In offline mode, it returns the persisted spec instead.
+func getSpec(sdAPI api.API, smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	if r := versionRange(smallSpec.Version); r != nil {
+		spec, err := getSpecInRange(sdAPI, smallSpec, r)
+		if err == nil {
+			return spec, nil
+		}
+		lgr.Debug.Printf("failed to resolve %v locally: %v", smallSpec.Version, err)
+	}
+
+	if isOffline {
+		lgr.Debug.Println("offline mode, load the cached command spec.")
+		metadata, err := cache.Resolve(smallSpec.Namespace, smallSpec.Name, smallSpec.Version)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get command in offline mode: %v", err)
+		}
+		lgr.Debug.Printf("resolved to version %v fetched at %v", metadata.Spec.Version, metadata.FetchedAt)
+		if isExplainResolution {
+			explainResolvedBy(smallSpec.Version, metadata.Spec.Version, "the cached spec")
+		}
+		return metadata.Spec, nil
+	}
+
+	spec, err := sdAPI.GetCommand(smallSpec)
+	if err != nil {
+		return nil, err
+	}
+	if isExplainResolution {
+		explainResolvedBy(smallSpec.Version, spec.Version, "Screwdriver API")
+	}
+	if err := cache.SaveSpec(smallSpec.Version, spec); err != nil {
+		lgr.Debug.Printf("failed to cache the command spec: %v", err)
+	}
+	return spec, nil
+}
+
 // New returns each format type of Executor
 func New(sdAPI api.API, args []string) (Executor, error) {
 	args, err := parseExecSubCommands(args)
 	if err != nil {
 		return nil, err
 	}
+	isOffline = isOffline || config.Offline
 
 	smallSpec, pos, err := util.SplitCmdWithSearch(args)
 	if err != nil {
@@ -94,26 +157,79 @@ func New(sdAPI api.API, args []string) (Executor, error) {
 	if err != nil {
 		return nil, err
 	}
+	report = newReport(smallSpec)
+	if err := prepareCapture(smallSpec); err != nil {
+		return nil, err
+	}
 
 	sdAPI.SetVerbose(isVerbose)
 
-	spec, err := sdAPI.GetCommand(smallSpec)
+	spec, err := getLockedSpec(sdAPI, smallSpec)
 	if err != nil {
 		return nil, err
 	}
 
+	report.recordSpec(spec)
+
+	execTimeout, err = commandTimeout(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// the docker client needs its own environment, the container gets the declared variables only anyway
+	commandEnviron, err = commandEnv(spec, (isCleanEnv || config.CleanEnv) && spec.Format != "docker")
+	if err != nil {
+		return nil, err
+	}
+
+	// check the arguments before downloading so that a typo fails quickly
+	if err := spec.Args.Check(args[pos+1:]); err != nil {
+		usage := spec.Args.Usage(fmt.Sprintf("sd-cmd exec %s/%s@%s", spec.Namespace, spec.Name, smallSpec.Version))
+		return nil, fmt.Errorf("%v\n%s", err, usage)
+	}
+
 	switch spec.Format {
 	case "binary":
 		return NewBinary(spec, args[pos+1:], isVerbose)
 	case "habitat":
 		return NewHabitat(spec, args[pos+1:], isVerbose)
 	case "docker":
-		return nil, errors.New("the docker format is not yet implemented")
+		return NewDocker(spec, args[pos+1:], isVerbose)
+	case "archive":
+		return NewArchive(spec, args[pos+1:], isVerbose)
+	case "script":
+		return NewScript(spec, args[pos+1:], isVerbose)
 	default:
 		return nil, errors.New("the format is not allowed")
 	}
 }
 
+// IsInstalled reports whether the command of spec is installed in the local cache.
+// A docker command and a remote habitat package are not installed by sd-cmd, so they are never regarded as installed.
+func IsInstalled(spec *util.CommandSpec) bool {
+	if lgr == nil {
+		// the install checks log broken installations
+		lgr, _ = logger.New(nil)
+	}
+
+	switch spec.Format {
+	case "binary":
+		binary, err := NewBinary(spec, nil, false)
+		return err == nil && binary.isInstalled()
+	case "habitat":
+		habitat, err := NewHabitat(spec, nil, false)
+		return err == nil && spec.Habitat != nil && spec.Habitat.Mode == "local" && habitat.isDownloaded()
+	case "archive":
+		archive, err := NewArchive(spec, nil, false)
+		return err == nil && archive.isInstalled()
+	case "script":
+		script, err := NewScript(spec, nil, false)
+		return err == nil && script.isInstalled()
+	default:
+		return false
+	}
+}
+
 func execCommand(path string, args []string) (err error) {
 	cmd := command(path, args...)
 	if !terminal.IsTerminal(syscall.Stdin) {
@@ -122,10 +238,21 @@ func execCommand(path string, args []string) (err error) {
 
 	lgr.Debug.Println("mmmmmm START COMMAND OUTPUT mmmmmm")
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout, cmd.Stderr = capture.writers()
+	if commandEnviron != nil {
+		cmd.Env = commandEnviron
+	}
+	// run in its own process group so that signals reach the whole group including grandchildren
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
-	err = cmd.Run()
+	// relay signals before starting so that none of them terminates sd-cmd leaving the command orphaned
+	signals, stopSignals := notifySignals()
+	defer stopSignals()
+	err = cmd.Start()
+	if err == nil {
+		err = waitCommand(cmd, execTimeout, signals)
+		report.recordProcess(cmd.ProcessState)
+	}
 
 	lgr.Debug.Println("mmmmmm FINISH COMMAND OUTPUT mmmmmm")
 
@@ -142,4 +269,5 @@ func execCommand(path string, args []string) (err error) {
 // CleanUp close file you use.
 func CleanUp() {
 	lgr.Close()
+	capture.close()
 }
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -20,6 +21,8 @@ const (
 	binaryFormat  = "binary"
 	dockerFormat  = "docker"
 	habitatFormat = "habitat"
+	archiveFormat = "archive"
+	scriptFormat  = "script"
 )
 
 const (
@@ -36,6 +39,10 @@ const (
 	dummyHart        = "/dummy/" + dummyHartName
 	dummyCommand     = "dummy_get"
 	dummyImage       = "dummy:latest"
+	dummyArchive     = "/dummy/sd-step.tar.gz"
+	dummyEntrypoint  = "bin/sd-step"
+	dummyScript      = "/dummy/sd-step.sh"
+	dummyInterpreter = "sh"
 )
 
 var (
@@ -85,14 +92,35 @@ func teardown() {
 }
 
 type dummySDAPI struct {
-	spec *util.CommandSpec
-	err  error
+	spec        *util.CommandSpec
+	err         error
+	versions    []string
+	versionsErr error
+	// requested is versions which GetCommand is called with
+	requested []string
 }
 
 func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	d.requested = append(d.requested, smallSpec.Version)
 	return d.spec, d.err
 }
 
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return d.versions, d.versionsErr
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -174,6 +202,16 @@ func dummyCommandSpec(format string) (spec *util.CommandSpec) {
 			Image:   dummyImage,
 			Command: dummyCommand,
 		}
+	case archiveFormat:
+		spec.Archive = &util.Archive{
+			File:       dummyArchive,
+			Entrypoint: dummyEntrypoint,
+		}
+	case scriptFormat:
+		spec.Script = &util.Script{
+			File:        dummyScript,
+			Interpreter: dummyInterpreter,
+		}
 	}
 	return spec
 }
@@ -200,6 +238,27 @@ func TestNew(t *testing.T) {
 			debugFromEnv: false,
 			isLogFile:    false,
 		},
+		{
+			name:         "docker format success with no logging with file",
+			spec:         dummyCommandSpec(dockerFormat),
+			args:         []string{"exec", "ns/cmd@ver"},
+			debugFromEnv: false,
+			isLogFile:    false,
+		},
+		{
+			name:         "archive format success with no logging with file",
+			spec:         dummyCommandSpec(archiveFormat),
+			args:         []string{"exec", "ns/cmd@ver"},
+			debugFromEnv: false,
+			isLogFile:    false,
+		},
+		{
+			name:         "script format success with no logging with file",
+			spec:         dummyCommandSpec(scriptFormat),
+			args:         []string{"exec", "ns/cmd@ver"},
+			debugFromEnv: false,
+			isLogFile:    false,
+		},
 		{
 			name:         "should output log file by option",
 			spec:         dummyCommandSpec(binaryFormat),
@@ -282,6 +341,80 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewOffline(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	args := []string{"foo-dummy/name-dummy@stable"}
+
+	// failure. never cached
+	sdapi := newDummySDAPI(nil, fmt.Errorf("should not call API"))
+	_, err := New(sdapi, append([]string{"--offline"}, args...))
+	assert.NotNil(t, err)
+
+	// the spec is cached on executing online
+	sdapi = newDummySDAPI(dummyCommandSpec(binaryFormat), nil)
+	_, err = New(sdapi, args)
+	assert.Nil(t, err)
+
+	// success by option
+	sdapi = newDummySDAPI(nil, fmt.Errorf("should not call API"))
+	executor, err := New(sdapi, append([]string{"--offline"}, args...))
+	assert.Nil(t, err)
+	bin, ok := executor.(*Binary)
+	if assert.True(t, ok) {
+		assert.Equal(t, dummyVersion, bin.Spec.Version)
+
+		// failure. the binary is not installed
+		err = bin.Run()
+		assert.NotNil(t, err)
+	}
+
+	// success by env
+	config.Offline = true
+	defer func() {
+		config.Offline = false
+		isOffline = false
+	}()
+	_, err = New(sdapi, args)
+	assert.Nil(t, err)
+}
+
+func TestNewWithArgs(t *testing.T) {
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Args = &util.ArgsSchema{
+		Arguments: []*util.ArgSpec{{Name: "target", Enum: []string{"prod", "stage"}, Required: true}},
+	}
+	sdapi := newDummySDAPI(spec, nil)
+
+	_, err := New(sdapi, []string{"foo-dummy/name-dummy@1", "prod"})
+	assert.Nil(t, err)
+
+	// failure. the usage is generated from the schema
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@1", "prd"})
+	assert.EqualError(t, err, `The value of the argument <target> is invalid: "prd" is not one of prod, stage
+Usage: sd-cmd exec foo-dummy/name-dummy@1 <target>
+Arguments:
+  <target> <prod|stage>  (required)
+`)
+}
+
+func TestIsInstalledByFormat(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+
+	spec := dummyCommandSpec(binaryFormat)
+	assert.False(t, IsInstalled(spec))
+
+	dirPath := filepath.Join(config.BaseCommandPath, dummyNameSpace, dummyName, dummyVersion)
+	os.MkdirAll(dirPath, 0777)
+	ioutil.WriteFile(filepath.Join(dirPath, dummyFileName), []byte("hello"), 0777)
+	assert.True(t, IsInstalled(spec))
+
+	// docker and remote habitat commands are not installed by sd-cmd
+	assert.False(t, IsInstalled(dummyCommandSpec(dockerFormat)))
+	habitatSpec := dummyCommandSpec(habitatFormat)
+	habitatSpec.Habitat.Mode = "remote"
+	assert.False(t, IsInstalled(habitatSpec))
+}
+
 func TestCleanUp(t *testing.T) {
 	l := lgr
 	defer func() {
@@ -2,9 +2,12 @@ package executor
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/screwdriver-cd/sd-cmd/cache"
 	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/store"
 	"github.com/screwdriver-cd/sd-cmd/util"
@@ -34,7 +37,7 @@ func NewHabitat(spec *util.CommandSpec, args []string, isVerbose bool) (habitat
 }
 
 func (h *Habitat) getPkgDirPath() string {
-	return filepath.Join(config.BaseCommandPath, h.Spec.Namespace, h.Spec.Name, h.Spec.Version)
+	return cache.Dir(h.Spec.Namespace, h.Spec.Name, h.Spec.Version)
 }
 
 func (h *Habitat) getPkgFilePath() string {
@@ -50,33 +53,71 @@ func (h *Habitat) isDownloaded() bool {
 	if fInfo.Size() == 0 {
 		return false
 	}
+	if h.Spec.Habitat.Sha256 != "" {
+		body, err := ioutil.ReadFile(h.getPkgFilePath())
+		if err != nil {
+			return false
+		}
+		if err := verifyDigest(h.Spec.Habitat.Sha256, body); err != nil {
+			lgr.Debug.Printf("downloaded habitat package is broken: %v", err)
+			return false
+		}
+	}
 	return true
 }
 
 func (h *Habitat) download() error {
+	start := time.Now()
 	cmd, err := h.Store.GetCommand()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(h.getPkgDirPath(), 0777); err != nil {
-		return fmt.Errorf("Failed to create command directory: %v", err)
+	report.recordDownload(start, len(cmd.Body))
+	if err := verifyDigest(h.Spec.Habitat.Sha256, cmd.Body); err != nil {
+		return fmt.Errorf("Failed to verify downloaded package: %v", err)
 	}
+	return installFile(h.getPkgFilePath(), cmd.Body)
+}
 
-	path := h.getPkgFilePath()
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("Failed to create command file: %v", err)
+// prepare downloads the local mode package unless it is already downloaded.
+// The download is locked so that only one process downloads the same version at once.
+func (h *Habitat) prepare() error {
+	if h.Spec.Habitat.Mode != "local" {
+		return nil
+	}
+	if h.isDownloaded() {
+		report.recordCacheHit()
+		return nil
+	}
+	if isOffline {
+		return fmt.Errorf("The habitat package is not downloaded, it cannot be downloaded in offline mode")
 	}
-	defer file.Close()
 
-	_, err = file.Write(cmd.Body)
+	lgr.Debug.Println("waiting for the lock of habitat package.")
+	unlock, err := cache.Lock(h.Spec.Namespace, h.Spec.Name, h.Spec.Version)
 	if err != nil {
-		return fmt.Errorf("Failed to write command file: %v", err)
+		return err
+	}
+	defer unlock()
+
+	// another process may have downloaded it while waiting for the lock
+	if h.isDownloaded() {
+		lgr.Debug.Println("habitat package downloaded by another process, skip downloading.")
+		report.recordCacheHit()
+		return nil
 	}
-	if err := os.Chmod(path, 0777); err != nil {
-		return fmt.Errorf("Failed to change the access permissions of command file: %v", err)
+
+	lgr.Debug.Println("start downloading local mode habitat package.")
+	return h.download()
+}
+
+// signedBody returns the signed contents of the package.
+// A remote package has no file, so its package name is signed instead.
+func (h *Habitat) signedBody() ([]byte, error) {
+	if h.Spec.Habitat.Mode == "local" {
+		return ioutil.ReadFile(h.getPkgFilePath())
 	}
-	return nil
+	return []byte(h.Spec.Habitat.Package), nil
 }
 
 // install executes "hab install" with a package name
@@ -103,23 +144,35 @@ func (h *Habitat) exec() (err error) {
 func (h *Habitat) Run() (err error) {
 	lgr.Debug.Println("start installing habitat command.")
 
-	if h.Spec.Habitat.Mode == "local" && !h.isDownloaded() {
-		lgr.Debug.Println("start downloading local mode habitat package.")
+	err = h.prepare()
+	if err != nil {
+		lgr.Debug.Println(err)
+		return
+	}
+
+	lgr.Debug.Println("start verifying habitat command.")
+	err = verifySignature(h.Spec.Habitat.Signature, h.signedBody)
+	if err != nil {
+		lgr.Debug.Println(err)
+		return
+	}
 
-		err = h.download()
+	// a remote package cannot be installed without network, it is expected to be installed already
+	if h.Spec.Habitat.Mode == "local" || !isOffline {
+		err = h.install()
 		if err != nil {
 			lgr.Debug.Println(err)
 			return
 		}
 	}
 
-	err = h.install()
-	if err != nil {
-		lgr.Debug.Println(err)
-		return
+	habitatSpec := h.Spec
+	if habitatSpec.Habitat.Mode == "local" {
+		if err := cache.MarkUsed(habitatSpec.Namespace, habitatSpec.Name, habitatSpec.Version); err != nil {
+			lgr.Debug.Println(err)
+		}
 	}
 
-	habitatSpec := h.Spec
 	lgr.Debug.Println("start executing habitat command.")
 	lgr.Debug.Println("Namespace:", habitatSpec.Namespace, ",Name:", habitatSpec.Name, ",Version:", habitatSpec.Version)
 	err = h.exec()
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
 )
 
 var dummyArgs = []string{"arg1", "arg2"}
@@ -100,6 +101,13 @@ func TestRunHabitat(t *testing.T) {
 	hartPath := filepath.Join(config.BaseCommandPath, spec.Namespace, spec.Name, spec.Version, dummyEmptyFile)
 	os.Remove(hartPath)
 
+	// failure. the downloaded package does not match the digest
+	spec.Habitat.Sha256 = util.Sha256Sum([]byte(invalidShell))
+	err = hab.Run()
+	assert.Contains(t, fmt.Sprint(err), "Checksum mismatch")
+	assert.False(t, hab.isDownloaded())
+	spec.Habitat.Sha256 = ""
+
 	// store returns error
 	hab.Store = newDummyStore(validShell, spec, fmt.Errorf("store cause error"))
 	err = hab.Run()
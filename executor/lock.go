@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/lockfile"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// lookupLock returns the entry which the command is pinned to by the lockfile.
+// It returns nil if the command is not pinned, or an error in frozen mode.
+func lookupLock(smallSpec *util.CommandSpec) (*lockfile.Entry, error) {
+	ref := lockfile.Ref(smallSpec)
+	lock, err := lockfile.Load(config.LockfilePath)
+	if os.IsNotExist(err) {
+		if isFrozen {
+			return nil, fmt.Errorf("%s is not found in frozen mode", config.LockfilePath)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %s: %v", config.LockfilePath, err)
+	}
+
+	entry, ok := lock.Lookup(ref)
+	if !ok {
+		if isFrozen {
+			return nil, fmt.Errorf("%s is not locked in %s", ref, config.LockfilePath)
+		}
+		lgr.Debug.Printf("%v is not locked in %v", ref, config.LockfilePath)
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// verifyLock checks that the spec is the same command as the locked one
+func verifyLock(entry *lockfile.Entry, spec *util.CommandSpec) error {
+	if spec.Version != entry.Version {
+		return fmt.Errorf("The version %s does not match %s locked in %s", spec.Version, entry.Version, config.LockfilePath)
+	}
+	if entry.Sha256 != "" && lockfile.Digest(spec) != entry.Sha256 {
+		return fmt.Errorf("The digest of %s/%s@%s does not match %s", spec.Namespace, spec.Name, spec.Version, config.LockfilePath)
+	}
+	// the binary for the running platform is checked if the binary has variants for multiple platforms
+	if len(entry.Platforms) > 0 {
+		platform := platformOS + "/" + platformArch
+		if digest, ok := entry.Platforms[platform]; !ok || lockfile.PlatformDigests(spec)[platform] != digest {
+			return fmt.Errorf("The digest of %s/%s@%s for %s does not match %s", spec.Namespace, spec.Name, spec.Version, platform, config.LockfilePath)
+		}
+	}
+	return nil
+}
+
+// getLockedSpec returns the spec of the version which the command is pinned to by the lockfile.
+// If the command is not pinned, it returns the spec of the requested version.
+func getLockedSpec(sdAPI api.API, smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	entry, err := lookupLock(smallSpec)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return getSpec(sdAPI, smallSpec)
+	}
+
+	lgr.Debug.Printf("%v is locked to version %v", lockfile.Ref(smallSpec), entry.Version)
+	if isExplainResolution {
+		explainResolvedBy(smallSpec.Version, entry.Version, config.LockfilePath)
+	}
+	spec, err := getSpec(sdAPI, &util.CommandSpec{
+		Namespace: smallSpec.Namespace,
+		Name:      smallSpec.Name,
+		Version:   entry.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyLock(entry, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
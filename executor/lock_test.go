@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/lockfile"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+func TestNewWithLockfile(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	dir, _ := ioutil.TempDir("", "lockfile")
+	defer os.RemoveAll(dir)
+	config.LockfilePath = filepath.Join(dir, "sd-cmd.lock")
+	defer func() { config.LockfilePath = config.DefaultLockfilePath }()
+
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.Sha256 = "0123456789abcdef"
+	lock := lockfile.NewLockfile()
+	lock.Add("foo-dummy/name-dummy@stable", spec)
+	assert.Nil(t, lock.Save(config.LockfilePath))
+
+	// success. the locked version is requested instead of the tag
+	sdapi := &dummySDAPI{spec: dummyCommandSpec(binaryFormat)}
+	sdapi.spec.Binary.Sha256 = spec.Binary.Sha256
+	executor, err := New(sdapi, []string{"foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{dummyVersion}, sdapi.requested)
+	bin, ok := executor.(*Binary)
+	if assert.True(t, ok) {
+		assert.Equal(t, dummyVersion, bin.Spec.Version)
+	}
+
+	// success. a command which is not locked
+	sdapi.requested = nil
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@latest"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"latest"}, sdapi.requested)
+
+	// failure. a command which is not locked in frozen mode
+	_, err = New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@latest"})
+	assert.EqualError(t, err, fmt.Sprintf("foo-dummy/name-dummy@latest is not locked in %s", config.LockfilePath))
+	_, err = New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+
+	// failure. the digest is changed
+	sdapi.spec.Binary.Sha256 = "fedcba9876543210"
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@stable"})
+	assert.EqualError(t, err, fmt.Sprintf("The digest of foo-dummy/name-dummy@1.0.1 does not match %s", config.LockfilePath))
+
+	// failure. the version is changed
+	sdapi.spec = dummyCommandSpec(binaryFormat)
+	sdapi.spec.Version = "1.0.2"
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@stable"})
+	assert.NotNil(t, err)
+
+	// failure. no lockfile in frozen mode
+	os.Remove(config.LockfilePath)
+	_, err = New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@stable"})
+	assert.NotNil(t, err)
+	sdapi.requested = nil
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"stable"}, sdapi.requested)
+}
+
+func TestNewWithLockfilePlatforms(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	dir, _ := ioutil.TempDir("", "lockfile")
+	defer os.RemoveAll(dir)
+	config.LockfilePath = filepath.Join(dir, "sd-cmd.lock")
+	defer func() { config.LockfilePath = config.DefaultLockfilePath }()
+	defer func(goos, goarch string) {
+		platformOS, platformArch = goos, goarch
+	}(platformOS, platformArch)
+	platformOS, platformArch = "linux", "arm64"
+
+	newSpec := func(arm64Digest string) *util.CommandSpec {
+		spec := dummyCommandSpec(binaryFormat)
+		spec.Binary = &util.Binary{
+			Platforms: []*util.Binary{
+				{OS: "linux", Arch: "amd64", File: "sd-step-amd64", Sha256: "amd64-digest"},
+				{OS: "linux", Arch: "arm64", File: "sd-step-arm64", Sha256: arm64Digest},
+			},
+		}
+		return spec
+	}
+	lock := lockfile.NewLockfile()
+	lock.Add("foo-dummy/name-dummy@stable", newSpec("arm64-digest"))
+	assert.Nil(t, lock.Save(config.LockfilePath))
+
+	// success. the digest of the variant for the running platform matches
+	sdapi := &dummySDAPI{spec: newSpec("arm64-digest")}
+	_, err := New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+
+	// failure. the digest of the variant for the running platform is changed
+	sdapi.spec = newSpec("changed-digest")
+	_, err = New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@stable"})
+	assert.EqualError(t, err, fmt.Sprintf("The digest of foo-dummy/name-dummy@1.0.1 for linux/arm64 does not match %s", config.LockfilePath))
+
+	// failure. the running platform is not locked
+	platformOS, platformArch = "darwin", "arm64"
+	sdapi.spec = newSpec("arm64-digest")
+	_, err = New(sdapi, []string{"--frozen", "foo-dummy/name-dummy@stable"})
+	assert.EqualError(t, err, fmt.Sprintf("The digest of foo-dummy/name-dummy@1.0.1 for darwin/arm64 does not match %s", config.LockfilePath))
+}
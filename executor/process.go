@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+// forwardedSignals are sent to the process group of the command when sd-cmd receives them
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// notifySignals starts relaying forwardedSignals to the returned channel instead of terminating sd-cmd.
+// The returned function stops relaying.
+func notifySignals() (<-chan os.Signal, func()) {
+	signals := make(chan os.Signal, len(forwardedSignals))
+	signal.Notify(signals, forwardedSignals...)
+	return signals, func() { signal.Stop(signals) }
+}
+
+// waitCommand waits for the started command which runs in its own process group.
+// Received signals are forwarded to the process group.
+// If the timeout is exceeded, it sends SIGTERM to the process group,
+// then SIGKILL if the command does not exit within the grace period.
+func waitCommand(cmd *exec.Cmd, timeout time.Duration, signals <-chan os.Signal) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	pgid := cmd.Process.Pid
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+	for {
+		select {
+		case err := <-done:
+			return err
+		case sig := <-signals:
+			lgr.Debug.Printf("forward %v to the process group %d", sig, pgid)
+			syscall.Kill(-pgid, sig.(syscall.Signal))
+		case <-timeoutC:
+			lgr.Debug.Printf("command timed out after %v, send SIGTERM to the process group %d", timeout, pgid)
+			syscall.Kill(-pgid, syscall.SIGTERM)
+			select {
+			case <-done:
+			case <-time.After(config.TerminationGracePeriod):
+				lgr.Debug.Printf("command did not exit within %v, send SIGKILL to the process group %d", config.TerminationGracePeriod, pgid)
+				syscall.Kill(-pgid, syscall.SIGKILL)
+				<-done
+			}
+			return &TimeoutError{Path: cmd.Path, Timeout: timeout}
+		}
+	}
+}
+
+// ExitCode returns the exit code of sd-cmd for the error of executing a command.
+// A command killed by a signal is regarded as exited with 128 + the signal number like shells.
+// It returns false if the error is not from the executed command.
+func ExitCode(err error) (int, bool) {
+	switch e := err.(type) {
+	case *TimeoutError:
+		return TimeoutExitCode, true
+	case *exec.ExitError:
+		status, ok := e.Sys().(syscall.WaitStatus)
+		if !ok {
+			return 0, false
+		}
+		if status.Signaled() {
+			return 128 + int(status.Signal()), true
+		}
+		return status.ExitStatus(), true
+	default:
+		return 0, false
+	}
+}
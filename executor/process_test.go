@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startSignalHelper starts TestSignalHelperProcess which executes the script and waits until the script is ready
+func startSignalHelper(t *testing.T, dir, script string) *exec.Cmd {
+	helper := exec.Command(os.Args[0], "-test.run=TestSignalHelperProcess")
+	helper.Env = []string{
+		"GO_WANT_HELPER_PROCESS=1",
+		"PATH=" + os.Getenv("PATH"),
+		"HELPER_SCRIPT=" + script,
+		"HELPER_DIR=" + dir,
+	}
+	helper.Stderr = os.Stderr
+	if err := helper.Start(); err != nil {
+		t.Fatalf("failed to start helper: %v", err)
+	}
+
+	ready := filepath.Join(dir, "ready")
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(ready); err == nil {
+			return helper
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	helper.Process.Kill()
+	t.Fatalf("helper did not get ready")
+	return nil
+}
+
+func TestForwardSignals(t *testing.T) {
+	// the command killed by the forwarded signal makes sd-cmd exit with 128 + the signal number
+	for _, sig := range []syscall.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP} {
+		dir, _ := ioutil.TempDir("", "sd-cmd_signal")
+		helper := startSignalHelper(t, dir, `touch "$HELPER_DIR/ready"; exec sleep 30`)
+		helper.Process.Signal(sig)
+		err := helper.Wait()
+		if exitError, ok := err.(*exec.ExitError); assert.True(t, ok, sig.String()) {
+			assert.Equal(t, 128+int(sig), exitError.ExitCode(), sig.String())
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestForwardSignalsToGrandchildren(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_signal")
+	defer os.RemoveAll(dir)
+
+	// the grandchild is in the same process group, so it receives the forwarded signal as well
+	script := `
+trap 'echo child >> "$HELPER_DIR/log"; exit 0' TERM
+sh -c 'trap "echo grandchild >> \"$HELPER_DIR/log\"; exit 0" TERM; touch "$HELPER_DIR/ready"; while :; do sleep 0.1; done' &
+wait
+`
+	helper := startSignalHelper(t, dir, script)
+	helper.Process.Signal(syscall.SIGTERM)
+	assert.Nil(t, helper.Wait())
+
+	var log []byte
+	for i := 0; i < 50; i++ {
+		log, _ = ioutil.ReadFile(filepath.Join(dir, "log"))
+		if len(strings.Fields(string(log))) == 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.ElementsMatch(t, []string{"child", "grandchild"}, strings.Fields(string(log)))
+}
+
+func TestSignalHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	err := execCommand("/bin/sh", []string{"-c", os.Getenv("HELPER_SCRIPT")})
+	if code, ok := ExitCode(err); ok {
+		os.Exit(code)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestExitCode(t *testing.T) {
+	code, ok := ExitCode(&TimeoutError{Path: "/bin/sh", Timeout: time.Second})
+	assert.True(t, ok)
+	assert.Equal(t, TimeoutExitCode, code)
+
+	err := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	code, ok = ExitCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, code)
+
+	err = exec.Command("/bin/sh", "-c", "kill -TERM $$").Run()
+	code, ok = ExitCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, 143, code)
+
+	_, ok = ExitCode(fmt.Errorf("not executed"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// Report is a summary of an execution written as JSON next to the debug logs
+type Report struct {
+	// Command is the requested command reference as namespace/name@version
+	Command   string `json:"command"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Version is the exact version which Command is resolved to
+	Version string `json:"version"`
+	Format  string `json:"format"`
+	// CacheHit is true if the installed command is executed without downloading
+	CacheHit        bool      `json:"cacheHit"`
+	DownloadSize    int       `json:"downloadSize"`
+	DownloadSeconds float64   `json:"downloadSeconds"`
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+	ExitCode        int       `json:"exitCode"`
+	UserSeconds     float64   `json:"userSeconds"`
+	SystemSeconds   float64   `json:"systemSeconds"`
+}
+
+// report is the report of the current execution. It is nil until New is called.
+var report *Report
+
+// newReport returns the report of executing the command requested by smallSpec
+func newReport(smallSpec *util.CommandSpec) *Report {
+	return &Report{
+		Command:   fmt.Sprintf("%s/%s@%s", smallSpec.Namespace, smallSpec.Name, smallSpec.Version),
+		Namespace: smallSpec.Namespace,
+		Name:      smallSpec.Name,
+		StartTime: time.Now(),
+	}
+}
+
+func (r *Report) recordSpec(spec *util.CommandSpec) {
+	if r == nil {
+		return
+	}
+	r.Version = spec.Version
+	r.Format = spec.Format
+}
+
+func (r *Report) recordCacheHit() {
+	if r == nil {
+		return
+	}
+	r.CacheHit = true
+}
+
+func (r *Report) recordDownload(start time.Time, size int) {
+	if r == nil {
+		return
+	}
+	r.DownloadSize += size
+	r.DownloadSeconds += time.Since(start).Seconds()
+}
+
+// recordProcess adds the CPU time of the process. Some formats execute more than one process.
+func (r *Report) recordProcess(state *os.ProcessState) {
+	if r == nil || state == nil {
+		return
+	}
+	r.UserSeconds += state.UserTime().Seconds()
+	r.SystemSeconds += state.SystemTime().Seconds()
+}
+
+// path returns the path of the report file which is named like the debug log.
+// The process ID is added so that the reports of the executions started in the same second do not overwrite each other.
+func (r *Report) path() string {
+	filename := fmt.Sprintf("%v-%v-%v-%v.json", r.StartTime.Unix(), os.Getpid(), r.Namespace, r.Name)
+	return filepath.Join(config.SDArtifactsDir, ".sd", "commands", filename)
+}
+
+// WriteReport finishes the report of the execution with the error returned by Run and writes it
+// if it is enabled by --report or SD_CMD_REPORT.
+// A failure of writing the report does not fail the execution, so it is only logged.
+func WriteReport(runErr error) {
+	if report == nil || !(isReport || config.Report) {
+		return
+	}
+
+	report.EndTime = time.Now()
+	switch code, ok := ExitCode(runErr); {
+	case ok:
+		report.ExitCode = code
+	case runErr != nil:
+		report.ExitCode = 1
+	default:
+		report.ExitCode = 0
+	}
+
+	if err := report.write(); err != nil {
+		lgr.Debug.Printf("failed to write the execution report: %v", err)
+	}
+}
+
+func (r *Report) write() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert the report to json: %v", err)
+	}
+	path := r.path()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("Failed to create report directory: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0666); err != nil {
+		return fmt.Errorf("Failed to write report file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+// runWithReport executes the dummy binary command with --report and returns the written report
+func runWithReport(t *testing.T, body string) (*Report, error) {
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Binary.File = "report"
+	executor, err := New(newDummySDAPI(spec, nil), []string{"--report", "foo-dummy/name-dummy@stable"})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	bin := executor.(*Binary)
+	bin.Store = newDummyStore(body, spec, nil)
+	runErr := bin.Run()
+	WriteReport(runErr)
+
+	data, err := ioutil.ReadFile(report.path())
+	if err != nil {
+		return nil, err
+	}
+	written := new(Report)
+	if err := json.Unmarshal(data, written); err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+func TestWriteReport(t *testing.T) {
+	defer func() {
+		isReport = false
+		report = nil
+	}()
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	defer os.RemoveAll(filepath.Join(config.SDArtifactsDir, ".sd"))
+
+	// the command is downloaded
+	before := time.Now().Add(-time.Second)
+	written, err := runWithReport(t, validShell)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "foo-dummy/name-dummy@stable", written.Command)
+		assert.Equal(t, dummyNameSpace, written.Namespace)
+		assert.Equal(t, dummyName, written.Name)
+		assert.Equal(t, dummyVersion, written.Version)
+		assert.Equal(t, binaryFormat, written.Format)
+		assert.False(t, written.CacheHit)
+		assert.Equal(t, len(validShell), written.DownloadSize)
+		assert.True(t, written.StartTime.After(before))
+		assert.False(t, written.EndTime.Before(written.StartTime))
+		assert.Equal(t, 0, written.ExitCode)
+	}
+
+	// the installed command is executed
+	written, err = runWithReport(t, "")
+	if assert.Nil(t, err) {
+		assert.True(t, written.CacheHit)
+		assert.Equal(t, 0, written.DownloadSize)
+	}
+}
+
+func TestWriteReportExitCode(t *testing.T) {
+	defer func() {
+		isReport = false
+		report = nil
+	}()
+	defer os.RemoveAll(filepath.Join(config.SDArtifactsDir, ".sd"))
+
+	report = newReport(dummyCommandSpec(binaryFormat))
+	isReport = true
+	for expected, runErr := range map[int]error{
+		0:               nil,
+		1:               fmt.Errorf("failed to download"),
+		TimeoutExitCode: &TimeoutError{Path: "/bin/sh", Timeout: time.Second},
+	} {
+		WriteReport(runErr)
+		data, err := ioutil.ReadFile(report.path())
+		assert.Nil(t, err)
+		written := new(Report)
+		assert.Nil(t, json.Unmarshal(data, written))
+		assert.Equal(t, expected, written.ExitCode)
+	}
+
+	// the report file is unique per process
+	assert.Equal(t, fmt.Sprintf("%v-%v-%v-%v.json", report.StartTime.Unix(), os.Getpid(), dummyNameSpace, dummyName), filepath.Base(report.path()))
+
+	// not written unless enabled
+	os.RemoveAll(filepath.Join(config.SDArtifactsDir, ".sd"))
+	isReport = false
+	WriteReport(nil)
+	_, err := os.Stat(report.path())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteReportOnNewFailure(t *testing.T) {
+	defer func() {
+		isReport = false
+		report = nil
+	}()
+	defer os.RemoveAll(filepath.Join(config.SDArtifactsDir, ".sd"))
+
+	_, newErr := New(newDummySDAPI(nil, fmt.Errorf("not found")), []string{"--report", "foo-dummy/name-dummy@stable"})
+	assert.NotNil(t, newErr)
+	WriteReport(newErr)
+
+	data, err := ioutil.ReadFile(report.path())
+	if assert.Nil(t, err) {
+		written := new(Report)
+		assert.Nil(t, json.Unmarshal(data, written))
+		assert.Equal(t, "foo-dummy/name-dummy@stable", written.Command)
+		assert.Equal(t, "", written.Version)
+		assert.Equal(t, 1, written.ExitCode)
+	}
+}
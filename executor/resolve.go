@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// explainOutput is where the explanation of version resolution is printed.
+// It is not stdout so as not to mix with the output of the command.
+var explainOutput io.Writer = os.Stderr
+
+// versionRange returns the range if ver is a version range which is not an exact version.
+// It returns nil for an exact version or a tag.
+func versionRange(ver string) *util.Range {
+	if _, err := util.ParseVersion(ver); err == nil {
+		return nil
+	}
+	r, err := util.ParseRange(ver)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// loadVersions returns the published versions of the command.
+// It fetches them from Screwdriver API unless the cached version list is fresh (see config.VersionListTTL).
+// The cached version list is used in offline mode, or when Screwdriver API fails.
+func loadVersions(sdAPI api.API, smallSpec *util.CommandSpec) (*cache.VersionList, error) {
+	list, cacheErr := cache.LoadVersions(smallSpec.Namespace, smallSpec.Name)
+	if cacheErr == nil && (isOffline || time.Since(list.FetchedAt) < config.VersionListTTL) {
+		return list, nil
+	}
+	if isOffline {
+		return nil, fmt.Errorf("The versions of %s/%s have never been cached", smallSpec.Namespace, smallSpec.Name)
+	}
+
+	versions, err := sdAPI.GetCommandVersions(smallSpec)
+	if err != nil {
+		if cacheErr == nil {
+			lgr.Debug.Printf("failed to get versions, the versions cached at %v are used: %v", list.FetchedAt, err)
+			return list, nil
+		}
+		return nil, fmt.Errorf("Failed to get versions: %v", err)
+	}
+	if err := cache.SaveVersions(smallSpec.Namespace, smallSpec.Name, versions); err != nil {
+		lgr.Debug.Printf("failed to cache the versions: %v", err)
+	}
+	return &cache.VersionList{Versions: versions, FetchedAt: time.Now()}, nil
+}
+
+// resolveVersion returns the highest published version which satisfies the range
+func resolveVersion(sdAPI api.API, smallSpec *util.CommandSpec, r *util.Range) (string, error) {
+	list, err := loadVersions(sdAPI, smallSpec)
+	if err != nil {
+		return "", err
+	}
+
+	chosen, candidates := r.MaxSatisfying(list.Versions)
+	if isExplainResolution {
+		explainCandidates(smallSpec, list, candidates, chosen)
+	}
+	if chosen == "" {
+		return "", fmt.Errorf("No version of %s/%s satisfies %s", smallSpec.Namespace, smallSpec.Name, r)
+	}
+	return chosen, nil
+}
+
+// getSpecInRange resolves the version range without Screwdriver API if possible,
+// and returns the spec of the resolved version.
+func getSpecInRange(sdAPI api.API, smallSpec *util.CommandSpec, r *util.Range) (*util.CommandSpec, error) {
+	version, err := resolveVersion(sdAPI, smallSpec, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// a published version never changes, so the cached spec is used as it is
+	metadata, err := cache.ReadMetadata(smallSpec.Namespace, smallSpec.Name, version)
+	if err == nil {
+		lgr.Debug.Printf("resolved %v to version %v with the cached spec", smallSpec.Version, version)
+		return metadata.Spec, nil
+	}
+	if isOffline {
+		return nil, fmt.Errorf("%s/%s@%s has never been cached", smallSpec.Namespace, smallSpec.Name, version)
+	}
+
+	spec, err := sdAPI.GetCommand(&util.CommandSpec{
+		Namespace: smallSpec.Namespace,
+		Name:      smallSpec.Name,
+		Version:   version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.SaveSpec(smallSpec.Version, spec); err != nil {
+		lgr.Debug.Printf("failed to cache the command spec: %v", err)
+	}
+	return spec, nil
+}
+
+func explainCandidates(smallSpec *util.CommandSpec, list *cache.VersionList, candidates []util.Candidate, chosen string) {
+	fmt.Fprintf(explainOutput, "Resolving %s/%s@%s with %d versions fetched at %s\n",
+		smallSpec.Namespace, smallSpec.Name, smallSpec.Version, len(candidates), list.FetchedAt.Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(explainOutput, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  VERSION\tSATISFIES")
+	for _, candidate := range candidates {
+		mark := " "
+		if candidate.Version == chosen {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%t\n", mark, candidate.Version, candidate.Matched)
+	}
+	w.Flush()
+
+	if chosen == "" {
+		fmt.Fprintf(explainOutput, "No version satisfies %s\n", smallSpec.Version)
+		return
+	}
+	fmt.Fprintf(explainOutput, "Chosen version: %s\n", chosen)
+}
+
+// explainResolvedBy prints which version the tag or version was resolved to when it is not resolved locally
+func explainResolvedBy(requested, version, by string) {
+	fmt.Fprintf(explainOutput, "Resolved %s to %s by %s\n", requested, version, by)
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+func TestVersionRange(t *testing.T) {
+	for _, ver := range []string{"^1.0", "~1.0.1", "1.x", "1", ">=1.0.0,<2.0.0"} {
+		assert.NotNil(t, versionRange(ver), ver)
+	}
+	for _, ver := range []string{"1.0.1", "1.0.1-beta.1", "stable", "latest"} {
+		assert.Nil(t, versionRange(ver), ver)
+	}
+}
+
+func TestGetSpecInRange(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	defer func() { config.VersionListTTL = config.DefaultVersionListTTL }()
+	smallSpec := &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "^1.0"}
+	versions := []string{"1.0.0", dummyVersion, "2.0.0"}
+
+	// success. the versions are fetched and the resolved version is requested to API
+	sdapi := &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: versions}
+	spec, err := getSpec(sdapi, smallSpec)
+	assert.Nil(t, err)
+	assert.Equal(t, dummyVersion, spec.Version)
+	assert.Equal(t, []string{dummyVersion}, sdapi.requested)
+	list, err := cache.LoadVersions(dummyNameSpace, dummyName)
+	if assert.Nil(t, err) {
+		assert.Equal(t, versions, list.Versions)
+	}
+	metadata, err := cache.Resolve(dummyNameSpace, dummyName, "^1.0")
+	if assert.Nil(t, err) {
+		assert.Equal(t, dummyVersion, metadata.Spec.Version)
+	}
+
+	// success. resolved with the cached versions and spec without API while the versions are fresh
+	config.VersionListTTL = time.Hour
+	sdapi = &dummySDAPI{err: fmt.Errorf("should not call API"), versionsErr: fmt.Errorf("should not call API")}
+	spec, err = getSpec(sdapi, &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "~1.0.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, dummyVersion, spec.Version)
+	assert.Empty(t, sdapi.requested)
+
+	// success. resolved by API if no version satisfies the range
+	sdapi = &dummySDAPI{spec: dummyCommandSpec(binaryFormat)}
+	_, err = getSpec(sdapi, &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "^3"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"^3"}, sdapi.requested)
+
+	// success. the expired versions are fetched again
+	config.VersionListTTL = config.DefaultVersionListTTL
+	sdapi = &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: []string{dummyVersion, "1.1.0"}}
+	_, err = getSpec(sdapi, smallSpec)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1.0"}, sdapi.requested)
+
+	// success. the expired versions are used if API fails to return the versions
+	sdapi = &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versionsErr: fmt.Errorf("error")}
+	spec, err = getSpec(sdapi, smallSpec)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1.0"}, sdapi.requested)
+
+	// success. offline mode uses the expired versions
+	isOffline = true
+	defer func() { isOffline = false }()
+	sdapi = &dummySDAPI{err: fmt.Errorf("should not call API"), versionsErr: fmt.Errorf("should not call API")}
+	spec, err = getSpec(sdapi, &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "1.0.x"})
+	assert.Nil(t, err)
+	assert.Equal(t, dummyVersion, spec.Version)
+	assert.Empty(t, sdapi.requested)
+
+	// failure. the resolved version has never been cached in offline mode
+	_, err = getSpec(sdapi, &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "^2"})
+	assert.NotNil(t, err)
+}
+
+func TestGetSpecWithBrokenCache(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	smallSpec := &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: dummyVersion}
+
+	// spec.json written in the old format has no "spec" key
+	specPath := filepath.Join(cache.Dir(dummyNameSpace, dummyName, dummyVersion), "spec.json")
+	os.MkdirAll(filepath.Dir(specPath), 0777)
+	ioutil.WriteFile(specPath, []byte(`{"namespace":"foo-dummy","name":"name-dummy","version":"1.0.1"}`), 0666)
+	assert.Nil(t, cache.SaveVersions(dummyNameSpace, dummyName, []string{dummyVersion}))
+
+	// failure. it is not cached in offline mode
+	isOffline = true
+	_, err := getSpec(&dummySDAPI{err: fmt.Errorf("should not call API")}, smallSpec)
+	isOffline = false
+	assert.NotNil(t, err)
+
+	// success. the spec of the resolved version is fetched from API
+	sdapi := &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: []string{dummyVersion}}
+	spec, err := getSpec(sdapi, &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "^1.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, dummyVersion, spec.Version)
+	assert.Equal(t, []string{dummyVersion}, sdapi.requested)
+}
+
+func TestExplainResolution(t *testing.T) {
+	defer os.RemoveAll(filepath.Join(config.BaseCommandPath, dummyNameSpace))
+	output := bytes.NewBuffer(nil)
+	explainOutput = output
+	defer func() { explainOutput = os.Stderr }()
+
+	sdapi := &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: []string{"1.0.0", dummyVersion, "2.0.0"}}
+	_, err := New(sdapi, []string{"--explain-resolution", "foo-dummy/name-dummy@^1.0"})
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "Resolving foo-dummy/name-dummy@^1.0 with 3 versions fetched at ")
+	assert.Contains(t, output.String(), "  2.0.0")
+	assert.Contains(t, output.String(), "* 1.0.1")
+	assert.Contains(t, output.String(), "  1.0.0")
+	assert.Contains(t, output.String(), "Chosen version: 1.0.1\n")
+
+	output.Reset()
+	_, err = New(sdapi, []string{"--explain-resolution", "foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Resolved stable to 1.0.1 by Screwdriver API\n", output.String())
+
+	// not explained without the option
+	output.Reset()
+	_, err = New(sdapi, []string{"foo-dummy/name-dummy@stable"})
+	assert.Nil(t, err)
+	assert.Empty(t, output.String())
+}
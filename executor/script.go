@@ -0,0 +1,230 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/store"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+var (
+	lookPath = exec.LookPath
+	// interpreterVersionRegexp finds the first version in the output of "<interpreter> --version"
+	// such as "GNU bash, version 5.1.16(1)-release" or "v18.2.0"
+	interpreterVersionRegexp = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+	// knownInterpreterVersionRegexps find the version of the interpreters whose output has other numbers before the version.
+	// The key is the name of the interpreter without the version suffix (e.g. python for python3.8).
+	knownInterpreterVersionRegexps = map[string]*regexp.Regexp{
+		// This is perl 5, version 34, subversion 0 (v5.34.0) built for x86_64-linux-gnu-thread-multi
+		"perl": regexp.MustCompile(`\(v(\d+)\.(\d+)\.(\d+)\)`),
+		// Python 3.8.10
+		"python": regexp.MustCompile(`Python (\d+)(?:\.(\d+))?(?:\.(\d+))?`),
+	}
+)
+
+// Script is a Script Executor object
+type Script struct {
+	Args []string
+	// from SD API
+	Spec  *util.CommandSpec
+	Store store.Store
+	// Note: this property is set after downloaing a script via Store API
+	Command *store.Command
+}
+
+// NewScript returns Script object
+func NewScript(spec *util.CommandSpec, args []string, isVerbose bool) (*Script, error) {
+	if spec.Script == nil || spec.Script.Interpreter == "" {
+		return nil, fmt.Errorf("The interpreter of the script is not specified")
+	}
+
+	storeapi := store.New(config.SDStoreURL, spec, config.SDToken)
+
+	storeapi.SetVerbose(isVerbose)
+
+	script := &Script{
+		Args:  args,
+		Spec:  spec,
+		Store: storeapi,
+	}
+	return script, nil
+}
+
+func (s *Script) getDirPath() string {
+	return cache.Dir(s.Spec.Namespace, s.Spec.Name, s.Spec.Version)
+}
+
+func (s *Script) getFilePath() string {
+	if s.Spec.Script.File == "" {
+		return filepath.Join(s.getDirPath(), s.Spec.Name)
+	}
+	return filepath.Join(s.getDirPath(), filepath.Base(s.Spec.Script.File))
+}
+
+// parseInterpreterVersion returns the version in the output of the interpreter.
+// The first version is returned unless the interpreter is known to print other numbers before it.
+// Omitted minor and patch versions are regarded as 0.
+func parseInterpreterVersion(interpreter, output string) (*util.Version, error) {
+	versionRegexp, ok := knownInterpreterVersionRegexps[strings.TrimRight(filepath.Base(interpreter), "0123456789.")]
+	if !ok {
+		versionRegexp = interpreterVersionRegexp
+	}
+	values := versionRegexp.FindStringSubmatch(output)
+	if values == nil {
+		return nil, fmt.Errorf("No version is found in %q", output)
+	}
+	for i := 2; i <= 3; i++ {
+		if values[i] == "" {
+			values[i] = "0"
+		}
+	}
+	return util.ParseVersion(fmt.Sprintf("%s.%s.%s", values[1], values[2], values[3]))
+}
+
+// findInterpreter returns the path of the interpreter in PATH.
+// It also checks that the version of the interpreter is not lower than the minimum version.
+func (s *Script) findInterpreter() (string, error) {
+	script := s.Spec.Script
+	path, err := lookPath(script.Interpreter)
+	if err != nil {
+		return "", fmt.Errorf("The interpreter %s is not found in PATH: %v", script.Interpreter, err)
+	}
+	if script.MinVersion == "" {
+		return path, nil
+	}
+
+	minRange, err := util.ParseRange(">=" + script.MinVersion)
+	if err != nil {
+		return "", fmt.Errorf("The minimum version of the interpreter is invalid: %v", err)
+	}
+	output, err := command(path, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the version of the interpreter %s: %v", script.Interpreter, err)
+	}
+	version, err := parseInterpreterVersion(script.Interpreter, string(output))
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the version of the interpreter %s: %v", script.Interpreter, err)
+	}
+	if !minRange.Match(version) {
+		return "", fmt.Errorf("The interpreter %s %v is older than the minimum version %s", script.Interpreter, version, script.MinVersion)
+	}
+	return path, nil
+}
+
+func (s *Script) isInstalled() bool {
+	fInfo, err := os.Stat(s.getFilePath())
+	if err != nil || fInfo.Size() == 0 {
+		return false
+	}
+	if s.Spec.Script.Sha256 != "" {
+		body, err := ioutil.ReadFile(s.getFilePath())
+		if err != nil {
+			return false
+		}
+		if err := verifyDigest(s.Spec.Script.Sha256, body); err != nil {
+			lgr.Debug.Printf("installed script command is broken: %v", err)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Script) download() error {
+	start := time.Now()
+	cmd, err := s.Store.GetCommand()
+	if err != nil {
+		return err
+	}
+	report.recordDownload(start, len(cmd.Body))
+	s.Command = cmd
+	return nil
+}
+
+func (s *Script) install() error {
+	if err := verifyDigest(s.Spec.Script.Sha256, s.Command.Body); err != nil {
+		return fmt.Errorf("Failed to verify downloaded command: %v", err)
+	}
+	return installFile(s.getFilePath(), s.Command.Body)
+}
+
+// prepare downloads and installs the script unless it is already installed.
+// The installation is locked so that only one process downloads the same version at once.
+func (s *Script) prepare() error {
+	if s.isInstalled() {
+		lgr.Debug.Println("script command already installed, skip installation.")
+		report.recordCacheHit()
+		return nil
+	}
+	if isOffline {
+		return fmt.Errorf("The script command is not installed, it cannot be downloaded in offline mode")
+	}
+
+	lgr.Debug.Println("waiting for the lock of script command.")
+	unlock, err := cache.Lock(s.Spec.Namespace, s.Spec.Name, s.Spec.Version)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// another process may have installed it while waiting for the lock
+	if s.isInstalled() {
+		lgr.Debug.Println("script command installed by another process, skip installation.")
+		report.recordCacheHit()
+		return nil
+	}
+
+	lgr.Debug.Println("start downloading script command.")
+	if err := s.download(); err != nil {
+		return err
+	}
+
+	lgr.Debug.Println("start installing script command.")
+	return s.install()
+}
+
+// Run executes the script through the interpreter and returns output
+func (s *Script) Run() error {
+	interpreter, err := s.findInterpreter()
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	if err := s.prepare(); err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	lgr.Debug.Println("start verifying script command.")
+	err = verifySignature(s.Spec.Script.Signature, func() ([]byte, error) {
+		return ioutil.ReadFile(s.getFilePath())
+	})
+	if err != nil {
+		lgr.Debug.Println(err)
+		return err
+	}
+
+	scriptSpec := s.Spec
+	if err := cache.MarkUsed(scriptSpec.Namespace, scriptSpec.Name, scriptSpec.Version); err != nil {
+		lgr.Debug.Println(err)
+	}
+
+	lgr.Debug.Println("start executing script command.")
+	lgr.Debug.Println("Namespace:", scriptSpec.Namespace, ",Name:", scriptSpec.Name, ",Version:", scriptSpec.Version, ",Interpreter:", interpreter)
+	err = execCommand(interpreter, append([]string{s.getFilePath()}, s.Args...))
+	if err != nil {
+		lgr.Debug.Println(err)
+	} else {
+		lgr.Debug.Println("execute script command succeeded.")
+	}
+	return err
+}
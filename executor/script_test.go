@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// fakeInterpreter is an interpreter which prints its version and runs a script by sh
+const fakeInterpreter = `#!/bin/sh
+if [ "$1" = "--version" ]; then
+  echo "FakeLang 3.8.10 (default)"
+  exit 0
+fi
+exec /bin/sh "$@"
+`
+
+// setupInterpreter puts the fake interpreter in a temporary directory which is added to PATH
+func setupInterpreter(t *testing.T) (dir string, teardown func()) {
+	dir, _ = ioutil.TempDir("", "sd-cmd_script")
+	if err := ioutil.WriteFile(filepath.Join(dir, "fakelang"), []byte(fakeInterpreter), 0755); err != nil {
+		t.Fatalf("failed to write interpreter: %v", err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return dir, func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNewScript(t *testing.T) {
+	_, err := NewScript(dummyCommandSpec(scriptFormat), []string{"arg1", "arg2"}, false)
+	assert.Nil(t, err)
+
+	// failure. no interpreter
+	spec := dummyCommandSpec(scriptFormat)
+	spec.Script.Interpreter = ""
+	_, err = NewScript(spec, []string{}, false)
+	assert.NotNil(t, err)
+	spec.Script = nil
+	_, err = NewScript(spec, []string{}, false)
+	assert.NotNil(t, err)
+}
+
+func TestParseInterpreterVersion(t *testing.T) {
+	for output, expected := range map[string]string{
+		"Python 3.8.10":                         "3.8.10",
+		"GNU bash, version 5.1.16(1)-release":   "5.1.16",
+		"node v18.2":                            "18.2.0",
+		"perl 5, version 34, subversion 0 (v5)": "5.0.0",
+	} {
+		version, err := parseInterpreterVersion("fakelang", output)
+		if assert.Nil(t, err, output) {
+			assert.Equal(t, expected, version.String(), output)
+		}
+	}
+
+	_, err := parseInterpreterVersion("fakelang", "unknown")
+	assert.NotNil(t, err)
+
+	// the real output of the known interpreters
+	for _, c := range []struct {
+		interpreter string
+		output      string
+		expected    string
+	}{
+		{"perl", "\nThis is perl 5, version 34, subversion 0 (v5.34.0) built for x86_64-linux-gnu-thread-multi\n(with 60 registered patches, see perl -V for more detail)\n", "5.34.0"},
+		{"/usr/bin/perl", "\nThis is perl 5, version 36, subversion 0 (v5.36.0) built for x86_64-linux-gnu-thread-multi\n", "5.36.0"},
+		{"python3", "Python 3.11.7\n", "3.11.7"},
+		{"python3.8", "Python 3.8.10\n", "3.8.10"},
+		{"python", "Python 2.7.18\n", "2.7.18"},
+	} {
+		version, err := parseInterpreterVersion(c.interpreter, c.output)
+		if assert.Nil(t, err, c.output) {
+			assert.Equal(t, c.expected, version.String(), c.output)
+		}
+	}
+
+	// failure. the output of perl without the version
+	_, err = parseInterpreterVersion("perl", "This is perl 5, version 34, subversion 0")
+	assert.NotNil(t, err)
+}
+
+func TestFindInterpreter(t *testing.T) {
+	dir, teardown := setupInterpreter(t)
+	defer teardown()
+
+	spec := dummyCommandSpec(scriptFormat)
+	spec.Script.Interpreter = "fakelang"
+	script, _ := NewScript(spec, []string{}, false)
+
+	// success
+	for _, minVersion := range []string{"", "3", "3.8", "3.8.10"} {
+		spec.Script.MinVersion = minVersion
+		path, err := script.findInterpreter()
+		assert.Nil(t, err, minVersion)
+		assert.Equal(t, filepath.Join(dir, "fakelang"), path, minVersion)
+	}
+
+	// failure. the interpreter is too old
+	spec.Script.MinVersion = "3.9"
+	_, err := script.findInterpreter()
+	assert.EqualError(t, err, "The interpreter fakelang 3.8.10 is older than the minimum version 3.9")
+
+	// failure. the interpreter is not found
+	spec.Script.Interpreter = "notexistlang"
+	_, err = script.findInterpreter()
+	assert.Contains(t, fmt.Sprint(err), "The interpreter notexistlang is not found in PATH")
+}
+
+func TestRunScript(t *testing.T) {
+	dir, teardown := setupInterpreter(t)
+	defer teardown()
+
+	outPath := filepath.Join(dir, "out")
+	body := fmt.Sprintf("echo \"$@\" > %s\n", outPath)
+	spec := dummyCommandSpec(scriptFormat)
+	spec.Script.Interpreter = "fakelang"
+	spec.Script.MinVersion = "3.8"
+	spec.Script.Sha256 = util.Sha256Sum([]byte(body))
+	script, _ := NewScript(spec, []string{"arg1", "arg2"}, false)
+	defer os.RemoveAll(script.getDirPath())
+	assert.Equal(t, filepath.Join(config.BaseCommandPath, "foo-dummy/name-dummy/1.0.1/sd-step.sh"), script.getFilePath())
+
+	// failure. the downloaded script does not match the digest
+	script.Store = newDummyStore("echo broken\n", spec, nil)
+	err := script.Run()
+	assert.Contains(t, fmt.Sprint(err), "Checksum mismatch")
+	assert.False(t, script.isInstalled())
+
+	// success. the script is run through the interpreter with the arguments
+	script.Store = newDummyStore(body, spec, nil)
+	assert.Nil(t, script.Run())
+	assert.True(t, script.isInstalled())
+	out, _ := ioutil.ReadFile(outPath)
+	assert.Equal(t, "arg1 arg2", strings.TrimSpace(string(out)))
+
+	// failure. the interpreter is too old, so the script is not run
+	os.Remove(outPath)
+	spec.Script.MinVersion = "4"
+	assert.NotNil(t, script.Run())
+	_, err = os.Stat(outPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// TimeoutExitCode is the exit code of sd-cmd when the command timed out
+const TimeoutExitCode = 124
+
+// TimeoutError is returned when the command did not finish within the timeout
+type TimeoutError struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("The command %s timed out after %v", e.Path, e.Timeout)
+}
+
+// commandTimeout returns the timeout given by --timeout, or the default timeout of the command spec
+func commandTimeout(spec *util.CommandSpec) (time.Duration, error) {
+	if execTimeout > 0 || spec.Timeout == "" {
+		return execTimeout, nil
+	}
+	timeout, err := time.ParseDuration(spec.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("The timeout of the command spec is invalid: %v", err)
+	}
+	return timeout, nil
+}
@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	defer func() { execTimeout = 0 }()
+
+	spec := dummyCommandSpec(binaryFormat)
+	timeout, err := commandTimeout(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	// the default timeout of the spec
+	spec.Timeout = "10m"
+	timeout, err = commandTimeout(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, timeout)
+
+	// --timeout takes precedence over the spec
+	execTimeout = time.Minute
+	timeout, err = commandTimeout(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, timeout)
+
+	// failure. invalid timeout of the spec
+	execTimeout = 0
+	spec.Timeout = "ten minutes"
+	_, err = commandTimeout(spec)
+	assert.NotNil(t, err)
+}
+
+func TestNewWithTimeout(t *testing.T) {
+	defer func() { execTimeout = 0 }()
+
+	sdapi := newDummySDAPI(dummyCommandSpec(binaryFormat), nil)
+	_, err := New(sdapi, []string{"--timeout", "90s", "ns/cmd@ver"})
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, execTimeout)
+
+	spec := dummyCommandSpec(binaryFormat)
+	spec.Timeout = "5m"
+	_, err = New(newDummySDAPI(spec, nil), []string{"ns/cmd@ver"})
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Minute, execTimeout)
+
+	// failure. invalid duration
+	_, err = New(sdapi, []string{"--timeout", "forever", "ns/cmd@ver"})
+	assert.NotNil(t, err)
+}
+
+func TestExecCommandTimeout(t *testing.T) {
+	defer func(period time.Duration) {
+		execTimeout = 0
+		config.TerminationGracePeriod = period
+	}(config.TerminationGracePeriod)
+	execTimeout = 200 * time.Millisecond
+	config.TerminationGracePeriod = 200 * time.Millisecond
+
+	// success. the command finishes within the timeout
+	assert.Nil(t, execCommand("/bin/sh", []string{"-c", "exit 0"}))
+
+	// the exit status of the command is returned as it is
+	err := execCommand("/bin/sh", []string{"-c", "exit 3"})
+	if exitError, ok := err.(*exec.ExitError); assert.True(t, ok) {
+		assert.Equal(t, 3, exitError.ExitCode())
+	}
+
+	// failure. the command is terminated by SIGTERM
+	start := time.Now()
+	err = execCommand("/bin/sh", []string{"-c", "sleep 10"})
+	assert.EqualError(t, err, "The command /bin/sh timed out after 200ms")
+	assert.IsType(t, &TimeoutError{}, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	// failure. the command ignoring SIGTERM is killed by SIGKILL after the grace period
+	start = time.Now()
+	err = execCommand("/bin/sh", []string{"-c", "trap '' TERM; sleep 10; sleep 10"})
+	assert.IsType(t, &TimeoutError{}, err)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 400*time.Millisecond, elapsed)
+	assert.True(t, elapsed < 5*time.Second, elapsed)
+}
@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// verifyDigest checks that the sha256 digest of body matches the expected one.
+// A command published without a digest is not checked.
+func verifyDigest(expected string, body []byte) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := util.Sha256Sum(body); actual != expected {
+		return fmt.Errorf("Checksum mismatch: expected sha256 %s, but got %s", expected, actual)
+	}
+	return nil
+}
+
+// verifySignature checks the signature of the command file loaded by load with the trusted keys.
+// It is skipped unless the trusted keys are configured, which is a configuration error with the enforce policy.
+// An unsigned command is refused or only warned according to the signature policy.
+func verifySignature(sig *util.Signature, load func() ([]byte, error)) error {
+	if config.TrustedKeysPath == "" {
+		if config.SignaturePolicy == config.SignaturePolicyEnforce {
+			return fmt.Errorf("SD_CMD_SIGNATURE_POLICY is %s, but SD_CMD_TRUSTED_KEYS is not set", config.SignaturePolicyEnforce)
+		}
+		return nil
+	}
+	if sig == nil {
+		if config.SignaturePolicy == config.SignaturePolicyEnforce {
+			return fmt.Errorf("The command is not signed")
+		}
+		lgr.Error.Println("WARNING: The command is not signed")
+		return nil
+	}
+
+	keys, err := util.LoadTrustedKeys(config.TrustedKeysPath)
+	if err != nil {
+		return err
+	}
+	body, err := load()
+	if err != nil {
+		return fmt.Errorf("Failed to load command file: %v", err)
+	}
+	return util.VerifySignature(sig, body, keys)
+}
+
+// verifyUnsignable checks the signature policy for a command which can not be signed such as a docker image.
+// It is refused with the enforce policy, and warned if the trusted keys are configured.
+func verifyUnsignable(format string) error {
+	if config.SignaturePolicy == config.SignaturePolicyEnforce {
+		return fmt.Errorf("A %s command can not be signed, so it is refused by SD_CMD_SIGNATURE_POLICY=%s", format, config.SignaturePolicyEnforce)
+	}
+	if config.TrustedKeysPath != "" {
+		lgr.Error.Printf("WARNING: A %s command can not be signed, so it is not verified\n", format)
+	}
+	return nil
+}
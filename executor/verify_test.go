@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	body := []byte(validShell)
+
+	// no digest
+	assert.Nil(t, verifyDigest("", body))
+
+	// digest matches
+	assert.Nil(t, verifyDigest(util.Sha256Sum(body), body))
+
+	// digest does not match
+	assert.NotNil(t, verifyDigest(util.Sha256Sum(body), body[:len(body)-1]))
+}
+
+func TestVerifySignature(t *testing.T) {
+	keysPath, policy := config.TrustedKeysPath, config.SignaturePolicy
+	defer func() {
+		config.TrustedKeysPath, config.SignaturePolicy = keysPath, policy
+	}()
+
+	dir, _ := ioutil.TempDir("", "sd-cmd_verify")
+	defer os.RemoveAll(dir)
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	config.TrustedKeysPath = filepath.Join(dir, "trusted.keys")
+	ioutil.WriteFile(config.TrustedKeysPath, []byte(base64.StdEncoding.EncodeToString(pub)), 0600)
+
+	body := []byte(validShell)
+	load := func() ([]byte, error) { return body, nil }
+	sig := util.Sign(priv, body)
+
+	// success
+	assert.Nil(t, verifySignature(sig, load))
+
+	// failure. the body is tampered
+	assert.NotNil(t, verifySignature(sig, func() ([]byte, error) { return []byte(invalidShell), nil }))
+
+	// unsigned command is warned
+	config.SignaturePolicy = config.SignaturePolicyWarn
+	assert.Nil(t, verifySignature(nil, load))
+
+	// unsigned command is refused
+	config.SignaturePolicy = config.SignaturePolicyEnforce
+	assert.NotNil(t, verifySignature(nil, load))
+
+	// failure. no trusted keys with the enforce policy
+	config.TrustedKeysPath = ""
+	assert.EqualError(t, verifySignature(sig, load), "SD_CMD_SIGNATURE_POLICY is enforce, but SD_CMD_TRUSTED_KEYS is not set")
+
+	// not verified without trusted keys
+	config.SignaturePolicy = config.SignaturePolicyWarn
+	assert.Nil(t, verifySignature(nil, load))
+}
+
+func TestVerifyUnsignable(t *testing.T) {
+	keysPath, policy := config.TrustedKeysPath, config.SignaturePolicy
+	defer func() {
+		config.TrustedKeysPath, config.SignaturePolicy = keysPath, policy
+	}()
+
+	config.SignaturePolicy = config.SignaturePolicyWarn
+	assert.Nil(t, verifyUnsignable(dockerFormat))
+	config.TrustedKeysPath = "trusted.keys"
+	assert.Nil(t, verifyUnsignable(dockerFormat))
+
+	config.SignaturePolicy = config.SignaturePolicyEnforce
+	assert.EqualError(t, verifyUnsignable(dockerFormat), "A docker command can not be signed, so it is refused by SD_CMD_SIGNATURE_POLICY=enforce")
+}
@@ -0,0 +1,156 @@
+// Package info describes a published command without executing it.
+package info
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/executor"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// labelWidth is the width of the labels in the human-readable output
+const labelWidth = 13
+
+// Info is a type to describe a command
+type Info struct {
+	smallSpec *util.CommandSpec
+	sdAPI     api.API
+	isJSON    bool
+	out       io.Writer
+}
+
+// Description is the description of a command printed as JSON
+type Description struct {
+	// Command is the requested command reference as namespace/name@version
+	Command string            `json:"command"`
+	Spec    *util.CommandSpec `json:"spec"`
+	// Installed is true if the resolved version is installed in the local cache
+	Installed bool `json:"installed"`
+	// Path is the directory which the command is installed in
+	Path string `json:"path,omitempty"`
+}
+
+// New generates new Info.
+// args is expected as [options..., "namespace/name@version"]
+func New(api api.API, args []string) (i *Info, err error) {
+	i = &Info{sdAPI: api, out: os.Stdout}
+
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.BoolVar(&i.isJSON, "json", false, "Output the description as JSON")
+	err = fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("namespace/name@version is required")
+	}
+
+	i.smallSpec, err = util.SplitCmd(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// describe gets the command spec and checks whether it is installed
+func (i *Info) describe() (*Description, error) {
+	spec, err := i.sdAPI.GetCommand(i.smallSpec)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get command: %v", err)
+	}
+
+	d := &Description{
+		Command:   fmt.Sprintf("%s/%s@%s", i.smallSpec.Namespace, i.smallSpec.Name, i.smallSpec.Version),
+		Spec:      spec,
+		Installed: executor.IsInstalled(spec),
+	}
+	if d.Installed {
+		d.Path = cache.Dir(spec.Namespace, spec.Name, spec.Version)
+	}
+	return d, nil
+}
+
+// printField prints a label and its value. The lines of a multi-line value are indented under the first line.
+func (i *Info) printField(label, value string) {
+	value = strings.TrimRight(value, "\n")
+	if value == "" {
+		return
+	}
+	indent := "\n" + strings.Repeat(" ", labelWidth)
+	fmt.Fprintf(i.out, "%-*s%s\n", labelWidth, label+":", strings.Replace(value, "\n", indent, -1))
+}
+
+// printFormat prints the details specific to the format of the command
+func (i *Info) printFormat(spec *util.CommandSpec) {
+	switch {
+	case spec.Format == "binary" && spec.Binary != nil:
+		if len(spec.Binary.Platforms) == 0 {
+			i.printField("Binary", spec.Binary.File)
+		}
+		for _, variant := range spec.Binary.Platforms {
+			i.printField("Binary", fmt.Sprintf("%s (%s)", variant.File, variant.Platform()))
+		}
+	case spec.Format == "habitat" && spec.Habitat != nil:
+		i.printField("Mode", spec.Habitat.Mode)
+		i.printField("Package", spec.Habitat.Package)
+		i.printField("Command", spec.Habitat.Command)
+	case spec.Format == "docker" && spec.Docker != nil:
+		i.printField("Image", spec.Docker.Image)
+		i.printField("Command", spec.Docker.Command)
+	case spec.Format == "archive" && spec.Archive != nil:
+		i.printField("Archive", spec.Archive.File)
+		i.printField("Entrypoint", spec.Archive.Entrypoint)
+	case spec.Format == "script" && spec.Script != nil:
+		i.printField("Script", spec.Script.File)
+		interpreter := spec.Script.Interpreter
+		if spec.Script.MinVersion != "" {
+			interpreter += fmt.Sprintf(" (>= %s)", spec.Script.MinVersion)
+		}
+		i.printField("Interpreter", interpreter)
+	}
+}
+
+func (i *Info) print(d *Description) {
+	spec := d.Spec
+	i.printField("Command", fmt.Sprintf("%s/%s", spec.Namespace, spec.Name))
+	i.printField("Version", spec.Version)
+	i.printField("Description", spec.Description)
+	i.printField("Usage", spec.Usage)
+	if spec.Args != nil {
+		i.printField("Arguments", spec.Args.Usage(fmt.Sprintf("sd-cmd exec %s/%s@%s", spec.Namespace, spec.Name, spec.Version)))
+	}
+	i.printField("Maintainer", spec.Maintainer)
+	i.printField("Format", spec.Format)
+	i.printFormat(spec)
+	if d.Installed {
+		i.printField("Installed", "yes ("+d.Path+")")
+	} else {
+		i.printField("Installed", "no")
+	}
+}
+
+// Run prints the description of the command
+func (i *Info) Run() error {
+	d, err := i.describe()
+	if err != nil {
+		return err
+	}
+
+	if !i.isJSON {
+		i.print(d)
+		return nil
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert the description to json: %v", err)
+	}
+	fmt.Fprintln(i.out, string(data))
+	return nil
+}
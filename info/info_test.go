@@ -0,0 +1,174 @@
+package info
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/cache"
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const (
+	dummyNameSpace = "foo-dummy"
+	dummyName      = "name-dummy"
+	dummyVersion   = "1.0.1"
+	dummyCommand   = dummyNameSpace + "/" + dummyName + "@stable"
+)
+
+type dummySDAPI struct {
+	spec *util.CommandSpec
+	err  error
+}
+
+func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	return d.spec, d.err
+}
+
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ValidateCommand(yamlString string) (*util.ValidateResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) TagCommand(spec *util.CommandSpec, targetVersion, tag string) (*util.TagResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) RemoveTagCommand(spec *util.CommandSpec, tag string) (*util.TagResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SetVerbose(isVerbose bool) {}
+
+func dummyBinarySpec() *util.CommandSpec {
+	return &util.CommandSpec{
+		Namespace:   dummyNameSpace,
+		Name:        dummyName,
+		Description: "Lorem ipsum dolor sit amet.\nConsectetur adipiscing elit.\n",
+		Maintainer:  "foo@bar.com",
+		Version:     dummyVersion,
+		Format:      "binary",
+		Binary:      &util.Binary{File: "./hello"},
+	}
+}
+
+func newInfo(t *testing.T, spec *util.CommandSpec, err error, args ...string) (*Info, *bytes.Buffer) {
+	i, newErr := New(&dummySDAPI{spec: spec, err: err}, append(args, dummyCommand))
+	if newErr != nil {
+		t.Fatalf("err=%v, want nil", newErr)
+	}
+	out := new(bytes.Buffer)
+	i.out = out
+	return i, out
+}
+
+func TestNew(t *testing.T) {
+	i, err := New(&dummySDAPI{}, []string{"--json", dummyCommand})
+	assert.Nil(t, err)
+	assert.True(t, i.isJSON)
+	assert.Equal(t, "stable", i.smallSpec.Version)
+
+	// failure. no command
+	_, err = New(&dummySDAPI{}, []string{"--json"})
+	assert.NotNil(t, err)
+
+	// failure. invalid command
+	_, err = New(&dummySDAPI{}, []string{"foo-dummy/name-dummy"})
+	assert.NotNil(t, err)
+
+	// failure. unknown flag
+	_, err = New(&dummySDAPI{}, []string{"--yaml", dummyCommand})
+	assert.NotNil(t, err)
+}
+
+func TestRun(t *testing.T) {
+	i, out := newInfo(t, dummyBinarySpec(), nil)
+	assert.Nil(t, i.Run())
+	expected := `Command:     foo-dummy/name-dummy
+Version:     1.0.1
+Description: Lorem ipsum dolor sit amet.
+             Consectetur adipiscing elit.
+Maintainer:  foo@bar.com
+Format:      binary
+Binary:      ./hello
+Installed:   no
+`
+	assert.Equal(t, expected, out.String())
+
+	// the details of each format
+	spec := dummyBinarySpec()
+	spec.Format = "docker"
+	spec.Docker = &util.Docker{Image: "alpine:3", Command: "echo"}
+	i, out = newInfo(t, spec, nil)
+	assert.Nil(t, i.Run())
+	assert.Contains(t, out.String(), "Image:       alpine:3\nCommand:     echo\n")
+
+	spec.Format = "habitat"
+	spec.Habitat = &util.Habitat{Mode: "remote", Package: "core/git/2.14.1", Command: "git"}
+	i, out = newInfo(t, spec, nil)
+	assert.Nil(t, i.Run())
+	assert.Contains(t, out.String(), "Mode:        remote\nPackage:     core/git/2.14.1\nCommand:     git\n")
+
+	spec.Format = "script"
+	spec.Script = &util.Script{File: "./hello.sh", Interpreter: "bash", MinVersion: "4"}
+	spec.Usage = "sd-cmd exec foo-dummy/name-dummy@1 <target>"
+	i, out = newInfo(t, spec, nil)
+	assert.Nil(t, i.Run())
+	assert.Contains(t, out.String(), "Usage:       sd-cmd exec foo-dummy/name-dummy@1 <target>\n")
+	assert.Contains(t, out.String(), "Script:      ./hello.sh\nInterpreter: bash (>= 4)\n")
+
+	// failure. failed to get command
+	i, _ = newInfo(t, nil, fmt.Errorf("not found"))
+	assert.EqualError(t, i.Run(), "Failed to get command: not found")
+}
+
+func TestRunInstalled(t *testing.T) {
+	defer func(path string) { config.BaseCommandPath = path }(config.BaseCommandPath)
+	config.BaseCommandPath, _ = ioutil.TempDir("", "sd-cmd_info")
+	defer os.RemoveAll(config.BaseCommandPath)
+
+	dir := cache.Dir(dummyNameSpace, dummyName, dummyVersion)
+	os.MkdirAll(dir, 0777)
+	ioutil.WriteFile(filepath.Join(dir, "hello"), []byte("hello"), 0777)
+
+	i, out := newInfo(t, dummyBinarySpec(), nil)
+	assert.Nil(t, i.Run())
+	assert.Contains(t, out.String(), "Installed:   yes ("+dir+")\n")
+
+	i, out = newInfo(t, dummyBinarySpec(), nil, "--json")
+	assert.Nil(t, i.Run())
+	d := new(Description)
+	if assert.Nil(t, json.Unmarshal(out.Bytes(), d)) {
+		assert.Equal(t, dummyCommand, d.Command)
+		assert.Equal(t, dummyVersion, d.Spec.Version)
+		assert.Equal(t, "./hello", d.Spec.Binary.File)
+		assert.True(t, d.Installed)
+		assert.Equal(t, dir, d.Path)
+	}
+}
@@ -0,0 +1,135 @@
+// Package lister lists and searches published commands.
+package lister
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const (
+	defaultPage  = 1
+	defaultCount = 50
+)
+
+// Lister is a type to list or search published commands
+type Lister struct {
+	sdAPI api.API
+	// keyword is searched for unless it is empty
+	keyword   string
+	namespace string
+	page      int
+	count     int
+	isJSON    bool
+	out       io.Writer
+}
+
+func newFlagSet(name string, l *Lister) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.IntVar(&l.page, "page", defaultPage, "Page number of the result")
+	fs.IntVar(&l.count, "count", defaultCount, "Number of commands per page")
+	fs.BoolVar(&l.isJSON, "json", false, "Output the commands as JSON")
+	return fs
+}
+
+func (l *Lister) validatePagination() error {
+	if l.page < 1 || l.count < 1 {
+		return fmt.Errorf("-page and -count must be positive")
+	}
+	return nil
+}
+
+// New generates new Lister to list commands.
+// args is expected as [options...]
+func New(api api.API, args []string) (l *Lister, err error) {
+	l = &Lister{sdAPI: api, out: os.Stdout}
+
+	fs := newFlagSet("list", l)
+	fs.StringVar(&l.namespace, "namespace", "", "List the commands in the namespace only")
+	err = fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	if fs.NArg() != 0 {
+		return nil, fmt.Errorf("%v is unexpected argument", fs.Arg(0))
+	}
+	if err := l.validatePagination(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// NewSearch generates new Lister to search commands.
+// args is expected as [options..., "keyword"...]. The keywords are joined with spaces.
+func NewSearch(api api.API, args []string) (l *Lister, err error) {
+	l = &Lister{sdAPI: api, out: os.Stdout}
+
+	fs := newFlagSet("search", l)
+	err = fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	l.keyword = strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if l.keyword == "" {
+		return nil, fmt.Errorf("keyword to search is not specified")
+	}
+	if err := l.validatePagination(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// summary returns the first line of the description
+func summary(description string) string {
+	return strings.SplitN(strings.TrimSpace(description), "\n", 2)[0]
+}
+
+func (l *Lister) print(specs []*util.CommandSpec) error {
+	if len(specs) == 0 {
+		fmt.Fprintln(l.out, "No command is found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(l.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tVERSION\tFORMAT\tDESCRIPTION")
+	for _, spec := range specs {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\n", spec.Namespace, spec.Name, spec.Version, spec.Format, summary(spec.Description))
+	}
+	return w.Flush()
+}
+
+// Run lists or searches the commands and prints them
+func (l *Lister) Run() error {
+	var (
+		specs []*util.CommandSpec
+		err   error
+	)
+	if l.keyword != "" {
+		specs, err = l.sdAPI.SearchCommands(l.keyword, l.page, l.count)
+	} else {
+		specs, err = l.sdAPI.ListCommands(l.namespace, l.page, l.count)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to get commands: %v", err)
+	}
+
+	if !l.isJSON {
+		return l.print(specs)
+	}
+	if specs == nil {
+		specs = []*util.CommandSpec{}
+	}
+	data, err := json.MarshalIndent(specs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert the commands to json: %v", err)
+	}
+	fmt.Fprintln(l.out, string(data))
+	return nil
+}
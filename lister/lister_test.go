@@ -0,0 +1,127 @@
+package lister
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const dummyCommands = `[
+	{"namespace":"foo","name":"bar","version":"1.0.1","format":"binary","description":"Lorem ipsum.\nDolor sit amet."},
+	{"namespace":"foo","name":"baz-long-name","version":"2.3.0","format":"docker","description":"Consectetur."}
+]`
+
+// newFakeAPI returns API whose server responds with code and body, and the query of the last request
+func newFakeAPI(t *testing.T, code int, body string) (api.API, *url.Values) {
+	query := new(url.Values)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v4/commands", r.URL.Path)
+		*query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return api.New(server.URL+"/v4/", "fake-sd-token"), query
+}
+
+func TestNew(t *testing.T) {
+	l, err := New(nil, []string{"-namespace", "foo", "-page", "2", "-count", "10", "-json"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "foo", l.namespace)
+		assert.Equal(t, 2, l.page)
+		assert.Equal(t, 10, l.count)
+		assert.True(t, l.isJSON)
+	}
+
+	l, err = New(nil, []string{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, defaultPage, l.page)
+		assert.Equal(t, defaultCount, l.count)
+	}
+
+	// failure. unexpected argument
+	_, err = New(nil, []string{"foo"})
+	assert.NotNil(t, err)
+
+	// failure. invalid pagination
+	_, err = New(nil, []string{"-page", "0"})
+	assert.NotNil(t, err)
+	_, err = New(nil, []string{"-count", "-1"})
+	assert.NotNil(t, err)
+}
+
+func TestNewSearch(t *testing.T) {
+	l, err := NewSearch(nil, []string{"-count", "5", "git", "clone"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "git clone", l.keyword)
+		assert.Equal(t, 5, l.count)
+	}
+
+	// failure. no keyword
+	_, err = NewSearch(nil, []string{"-json"})
+	assert.NotNil(t, err)
+
+	// failure. unknown flag
+	_, err = NewSearch(nil, []string{"-namespace", "foo", "git"})
+	assert.NotNil(t, err)
+}
+
+func TestRunList(t *testing.T) {
+	sdAPI, query := newFakeAPI(t, 200, dummyCommands)
+	l, _ := New(sdAPI, []string{"-namespace", "foo", "-page", "2"})
+	out := new(bytes.Buffer)
+	l.out = out
+	assert.Nil(t, l.Run())
+	assert.Equal(t, url.Values{"namespace": {"foo"}, "page": {"2"}, "count": {"50"}}, *query)
+	expected := `COMMAND            VERSION  FORMAT  DESCRIPTION
+foo/bar            1.0.1    binary  Lorem ipsum.
+foo/baz-long-name  2.3.0    docker  Consectetur.
+`
+	assert.Equal(t, expected, out.String())
+
+	// no command
+	sdAPI, _ = newFakeAPI(t, 200, "[]")
+	l, _ = New(sdAPI, []string{})
+	out.Reset()
+	l.out = out
+	assert.Nil(t, l.Run())
+	assert.Equal(t, "No command is found\n", out.String())
+
+	// failure. 4xx error
+	sdAPI, _ = newFakeAPI(t, 403, `{"statusCode": 403,"error": "Forbidden","message": "Access Denied"}`)
+	l, _ = New(sdAPI, []string{})
+	assert.EqualError(t, l.Run(), "Failed to get commands: Screwdriver API 403 Forbidden: Access Denied")
+}
+
+func TestRunSearch(t *testing.T) {
+	sdAPI, query := newFakeAPI(t, 200, dummyCommands)
+	l, _ := NewSearch(sdAPI, []string{"-json", "lorem ipsum"})
+	out := new(bytes.Buffer)
+	l.out = out
+	assert.Nil(t, l.Run())
+	assert.Equal(t, url.Values{"search": {"lorem ipsum"}, "page": {"1"}, "count": {"50"}}, *query)
+
+	var specs []*util.CommandSpec
+	if assert.Nil(t, json.Unmarshal(out.Bytes(), &specs)) && assert.Len(t, specs, 2) {
+		assert.Equal(t, "bar", specs[0].Name)
+		assert.Equal(t, "2.3.0", specs[1].Version)
+	}
+
+	// an empty result is an empty array
+	sdAPI, _ = newFakeAPI(t, 200, "[]")
+	l, _ = NewSearch(sdAPI, []string{"-json", "nothing"})
+	out.Reset()
+	l.out = out
+	assert.Nil(t, l.Run())
+	assert.Equal(t, "[]\n", out.String())
+}
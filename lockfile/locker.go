@@ -0,0 +1,106 @@
+package lockfile
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// Locker is a type to pin command references to exact versions
+type Locker struct {
+	sdAPI api.API
+	refs  []string
+	path  string
+}
+
+// New generates new Locker.
+// args is expected as [options..., "namespace/name@version"...]
+func New(api api.API, args []string) (l *Locker, err error) {
+	fs := flag.NewFlagSet("lock", flag.ContinueOnError)
+	refsPath := fs.String("f", "", "Path of a file which has a command reference per line")
+	err = fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+
+	refs := fs.Args()
+	if *refsPath != "" {
+		fileRefs, err := readRefs(*refsPath)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, fileRefs...)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no command to lock is specified")
+	}
+	for _, ref := range refs {
+		if _, err := util.SplitCmd(ref); err != nil {
+			return nil, fmt.Errorf("%v is invalid command reference: %v", ref, err)
+		}
+	}
+
+	l = &Locker{
+		sdAPI: api,
+		refs:  refs,
+		path:  config.LockfilePath,
+	}
+	return
+}
+
+// readRefs reads command references from the file.
+// Empty lines and lines starting with "#" are ignored.
+func readRefs(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var refs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %v", path, err)
+	}
+	return refs, nil
+}
+
+// Run resolves each command reference through Screwdriver API and writes the lockfile
+func (l *Locker) Run() error {
+	sort.Strings(l.refs)
+	lock := NewLockfile()
+	for _, ref := range l.refs {
+		if _, ok := lock.Lookup(ref); ok {
+			continue
+		}
+		smallSpec, err := util.SplitCmd(ref)
+		if err != nil {
+			return err
+		}
+		spec, err := l.sdAPI.GetCommand(smallSpec)
+		if err != nil {
+			return fmt.Errorf("Failed to resolve %s: %v", ref, err)
+		}
+		lock.Add(ref, spec)
+		fmt.Printf("Locking %v to %v\n", ref, spec.Version)
+	}
+
+	if err := lock.Save(l.path); err != nil {
+		return fmt.Errorf("Failed to save %s: %v", l.path, err)
+	}
+	return nil
+}
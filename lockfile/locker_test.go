@@ -0,0 +1,139 @@
+package lockfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+type dummySDAPI struct {
+	// versions maps requested versions to resolved versions
+	versions map[string]string
+}
+
+func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	version, ok := d.versions[smallSpec.Version]
+	if !ok {
+		return nil, fmt.Errorf("%s is not found", smallSpec.Version)
+	}
+	spec := dummySpec("binary")
+	spec.Namespace = smallSpec.Namespace
+	spec.Name = smallSpec.Name
+	spec.Version = version
+	return spec, nil
+}
+
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ValidateCommand(yamlString string) (*util.ValidateResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) TagCommand(spec *util.CommandSpec, targetVersion, tag string) (*util.TagResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) RemoveTagCommand(spec *util.CommandSpec, tag string) (*util.TagResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SetVerbose(isVerbose bool) {}
+
+func newDummySDAPI() api.API {
+	return &dummySDAPI{versions: map[string]string{
+		"stable": "1.0.1",
+		"^1.0":   "1.2.0",
+		"2.0.0":  "2.0.0",
+	}}
+}
+
+func TestNew(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "locker")
+	defer os.RemoveAll(dir)
+	refsPath := filepath.Join(dir, "refs.txt")
+	ioutil.WriteFile(refsPath, []byte("# commands of the pipeline\nfoo/bar@stable\n\n  foo/baz@1.2.3 - 2.0.0  \n"), 0644)
+
+	// success
+	l, err := New(newDummySDAPI(), []string{"foo/qux@^1.0"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"foo/qux@^1.0"}, l.refs)
+		assert.Equal(t, config.LockfilePath, l.path)
+	}
+	l, err = New(newDummySDAPI(), []string{"-f", refsPath, "foo/qux@^1.0"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"foo/qux@^1.0", "foo/bar@stable", "foo/baz@1.2.3 - 2.0.0"}, l.refs)
+	}
+
+	// failure
+	for _, args := range [][]string{
+		{},
+		{"-f", filepath.Join(dir, "notexist.txt")},
+		{"foo/bar"},
+		{"-unknown", "foo/bar@stable"},
+	} {
+		_, err = New(newDummySDAPI(), args)
+		assert.NotNil(t, err, args)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "locker")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sd-cmd.lock")
+
+	// success
+	l := &Locker{
+		sdAPI: newDummySDAPI(),
+		refs:  []string{"foo/bar@stable", "foo/baz@^1.0", "foo/bar@2.0.0", "foo/bar@stable"},
+		path:  path,
+	}
+	assert.Nil(t, l.Run())
+	lock, err := Load(path)
+	if assert.Nil(t, err) {
+		assert.Len(t, lock.Commands, 3)
+		for ref, version := range map[string]string{
+			"foo/bar@stable": "1.0.1",
+			"foo/baz@^1.0":   "1.2.0",
+			"foo/bar@2.0.0":  "2.0.0",
+		} {
+			entry, ok := lock.Lookup(ref)
+			if assert.True(t, ok, ref) {
+				assert.Equal(t, version, entry.Version)
+				assert.Equal(t, dummySha256, entry.Sha256)
+			}
+		}
+	}
+
+	// failure. the lockfile is not changed if a reference can not be resolved
+	l.refs = []string{"foo/bar@latest"}
+	assert.NotNil(t, l.Run())
+	lock, err = Load(path)
+	assert.Nil(t, err)
+	assert.Len(t, lock.Commands, 3)
+}
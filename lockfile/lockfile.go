@@ -0,0 +1,116 @@
+// Package lockfile pins command references to exact versions and digests.
+package lockfile
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// header is written at the top of the lockfile
+const header = "# This file is generated by \"sd-cmd lock\". Do not edit it manually.\n"
+
+// Entry is an exact version of a command which a reference is pinned to
+type Entry struct {
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+	Version   string `yaml:"version"`
+	Format    string `yaml:"format"`
+	Sha256    string `yaml:"sha256,omitempty"`
+	// Platforms are the sha256 digests of the variants of the binary for each platform (OS/Arch)
+	Platforms map[string]string `yaml:"platforms,omitempty"`
+}
+
+// Lockfile maps command references (namespace/name@version) to exact versions
+type Lockfile struct {
+	Commands map[string]*Entry `yaml:"commands"`
+}
+
+// NewLockfile returns an empty Lockfile
+func NewLockfile() *Lockfile {
+	return &Lockfile{Commands: make(map[string]*Entry)}
+}
+
+// Ref returns the reference of the command spec as namespace/name@version
+func Ref(smallSpec *util.CommandSpec) string {
+	return fmt.Sprintf("%s/%s@%s", smallSpec.Namespace, smallSpec.Name, smallSpec.Version)
+}
+
+// Digest returns the sha256 digest of the file of the command such as the binary or the habitat package.
+// It returns "" if the command has no digest.
+func Digest(spec *util.CommandSpec) string {
+	switch {
+	case spec.Binary != nil:
+		return spec.Binary.Sha256
+	case spec.Habitat != nil:
+		return spec.Habitat.Sha256
+	case spec.Archive != nil:
+		return spec.Archive.Sha256
+	case spec.Script != nil:
+		return spec.Script.Sha256
+	default:
+		return ""
+	}
+}
+
+// PlatformDigests returns the sha256 digests of the variants of the binary keyed by their platforms (OS/Arch).
+// It returns nil if the command has no variants.
+func PlatformDigests(spec *util.CommandSpec) map[string]string {
+	if spec.Binary == nil || len(spec.Binary.Platforms) == 0 {
+		return nil
+	}
+	digests := make(map[string]string, len(spec.Binary.Platforms))
+	for _, variant := range spec.Binary.Platforms {
+		digests[variant.Platform()] = variant.Sha256
+	}
+	return digests
+}
+
+// Load reads the lockfile.
+// It returns an error which satisfies os.IsNotExist if the lockfile does not exist.
+func Load(path string) (*Lockfile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := NewLockfile()
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %v", path, err)
+	}
+	if l.Commands == nil {
+		l.Commands = make(map[string]*Entry)
+	}
+	return l, nil
+}
+
+// Save writes the lockfile
+func (l *Lockfile) Save(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("Failed to convert to yaml: %v", err)
+	}
+
+	return util.WriteFileAtomically(path, append([]byte(header), data...), 0644)
+}
+
+// Add pins the reference to the command spec
+func (l *Lockfile) Add(ref string, spec *util.CommandSpec) {
+	l.Commands[ref] = &Entry{
+		Namespace: spec.Namespace,
+		Name:      spec.Name,
+		Version:   spec.Version,
+		Format:    spec.Format,
+		Sha256:    Digest(spec),
+		Platforms: PlatformDigests(spec),
+	}
+}
+
+// Lookup returns the entry which the reference is pinned to
+func (l *Lockfile) Lookup(ref string) (*Entry, bool) {
+	entry, ok := l.Commands[ref]
+	return entry, ok
+}
@@ -0,0 +1,117 @@
+package lockfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const (
+	dummyNameSpace = "foo-dummy"
+	dummyName      = "name-dummy"
+	dummyVersion   = "1.0.1"
+	dummySha256    = "0123456789abcdef"
+)
+
+func dummySpec(format string) *util.CommandSpec {
+	spec := &util.CommandSpec{
+		Namespace: dummyNameSpace,
+		Name:      dummyName,
+		Version:   dummyVersion,
+		Format:    format,
+	}
+	switch format {
+	case "binary":
+		spec.Binary = &util.Binary{File: "sd-step", Sha256: dummySha256}
+	case "habitat":
+		spec.Habitat = &util.Habitat{Mode: "remote", Package: "core/git", Sha256: dummySha256}
+	case "docker":
+		spec.Docker = &util.Docker{Image: "alpine:latest"}
+	case "script":
+		spec.Script = &util.Script{File: "sd-step.sh", Interpreter: "sh", Sha256: dummySha256}
+	case "archive":
+		spec.Archive = &util.Archive{File: "sd-step.tar.gz", Entrypoint: "bin/sd-step", Sha256: dummySha256}
+	}
+	return spec
+}
+
+func TestRef(t *testing.T) {
+	smallSpec := &util.CommandSpec{Namespace: dummyNameSpace, Name: dummyName, Version: "^1.0"}
+	assert.Equal(t, "foo-dummy/name-dummy@^1.0", Ref(smallSpec))
+}
+
+func TestDigest(t *testing.T) {
+	assert.Equal(t, dummySha256, Digest(dummySpec("binary")))
+	assert.Equal(t, dummySha256, Digest(dummySpec("habitat")))
+	assert.Equal(t, dummySha256, Digest(dummySpec("archive")))
+	assert.Equal(t, dummySha256, Digest(dummySpec("script")))
+	assert.Equal(t, "", Digest(dummySpec("docker")))
+}
+
+func TestPlatformDigests(t *testing.T) {
+	assert.Nil(t, PlatformDigests(dummySpec("binary")))
+	assert.Nil(t, PlatformDigests(dummySpec("docker")))
+
+	spec := dummySpec("binary")
+	spec.Binary = &util.Binary{
+		Platforms: []*util.Binary{
+			{OS: "linux", Arch: "amd64", File: "sd-step-amd64", Sha256: "amd64-digest"},
+			{OS: "linux", Arch: "arm64", File: "sd-step-arm64", Sha256: "arm64-digest"},
+		},
+	}
+	assert.Equal(t, "", Digest(spec))
+	assert.Equal(t, map[string]string{"linux/amd64": "amd64-digest", "linux/arm64": "arm64-digest"}, PlatformDigests(spec))
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "lockfile")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sd-cmd.lock")
+
+	// failure. not exist
+	_, err := Load(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// success
+	lock := NewLockfile()
+	lock.Add("foo-dummy/name-dummy@stable", dummySpec("binary"))
+	lock.Add("foo-dummy/name-dummy@^1.0", dummySpec("docker"))
+	assert.Nil(t, lock.Save(path))
+
+	data, _ := ioutil.ReadFile(path)
+	assert.True(t, strings.HasPrefix(string(data), header))
+
+	loaded, err := Load(path)
+	if assert.Nil(t, err) {
+		assert.Equal(t, lock, loaded)
+		entry, ok := loaded.Lookup("foo-dummy/name-dummy@stable")
+		assert.True(t, ok)
+		assert.Equal(t, &Entry{
+			Namespace: dummyNameSpace,
+			Name:      dummyName,
+			Version:   dummyVersion,
+			Format:    "binary",
+			Sha256:    dummySha256,
+		}, entry)
+		_, ok = loaded.Lookup("foo-dummy/name-dummy@latest")
+		assert.False(t, ok)
+	}
+
+	// success. empty lockfile
+	ioutil.WriteFile(path, []byte(header), 0644)
+	loaded, err = Load(path)
+	if assert.Nil(t, err) {
+		assert.Empty(t, loaded.Commands)
+	}
+
+	// failure. broken lockfile
+	ioutil.WriteFile(path, []byte("commands: ["), 0644)
+	_, err = Load(path)
+	assert.NotNil(t, err)
+}
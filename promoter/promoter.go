@@ -1,10 +1,13 @@
 package promoter
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/tags"
 	"github.com/screwdriver-cd/sd-cmd/util"
 )
 
@@ -14,11 +17,22 @@ type Promoter struct {
 	sdAPI         api.API
 	targetVersion string
 	tag           string
+	isDryRun      bool
 }
 
 // New generates new Promoter.
-// args is expected as ["namespace/name", "targetVersion", "tag"]
-func New(api api.API, args []string) (p *Promoter, err error) {
+// args is expected as ["namespace/name", "targetVersion", "tag"] with optional flags before them
+func New(sdAPI api.API, args []string) (p *Promoter, err error) {
+	fs := flag.NewFlagSet("promote", flag.ContinueOnError)
+	isDryRun := fs.Bool("dry-run", false, "Print the requests to Screwdriver API without sending them")
+	if err = fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	if *isDryRun {
+		sdAPI = api.DryRun(sdAPI, os.Stdout)
+	}
+
+	args = fs.Args()
 	if len(args) != 3 {
 		return nil, fmt.Errorf("parameters are not enough")
 	}
@@ -41,7 +55,8 @@ func New(api api.API, args []string) (p *Promoter, err error) {
 
 	p = &Promoter{
 		smallSpec:     smallSpec,
-		sdAPI:         api,
+		sdAPI:         sdAPI,
+		isDryRun:      *isDryRun,
 		targetVersion: targetVersion,
 		tag:           tag,
 	}
@@ -49,15 +64,21 @@ func New(api api.API, args []string) (p *Promoter, err error) {
 	return
 }
 
-// Run executes tag command API
+// Run executes tag command API and records the move of the tag
 func (p *Promoter) Run() (err error) {
+	// the previous version is known only if the tag is found or Screwdriver API says it does not exist
+	previous, isPreviousKnown := "", true
 	spec, err := p.sdAPI.GetCommand(p.smallSpec)
-	if err != nil {
+	if res, ok := err.(*api.ResponseError); ok && res.StatusCode == 404 {
 		fmt.Printf("%v does not exist yet\n", p.tag)
+	} else if err != nil {
+		isPreviousKnown = false
+		fmt.Printf("Failed to get the version of %v: %v\n", p.tag, err)
 	} else if spec.Version == p.targetVersion {
 		fmt.Printf("%v has been already tagged with %v\n", spec.Version, p.tag)
 		return
 	} else {
+		previous = spec.Version
 		fmt.Printf("Removing %v from %v\n", spec.Version, p.tag)
 	}
 	res, err := p.sdAPI.TagCommand(p.smallSpec, p.targetVersion, p.tag)
@@ -67,5 +88,28 @@ func (p *Promoter) Run() (err error) {
 	}
 
 	fmt.Printf("Promoting %v to %v\n", res.Version, res.Tag)
+
+	// the tag is not moved on dry run, so nothing is recorded
+	if p.isDryRun {
+		return
+	}
+	// a move from an unknown version can not be rolled back
+	if !isPreviousKnown {
+		fmt.Fprintf(os.Stderr, "WARNING: The promotion is not recorded in the tag history because the previous version of %v is unknown\n", p.tag)
+		return
+	}
+
+	// the promotion succeeded, so failing to record it is only warned
+	recordErr := tags.Record(&tags.Move{
+		Namespace: p.smallSpec.Namespace,
+		Name:      p.smallSpec.Name,
+		Tag:       p.tag,
+		From:      previous,
+		To:        res.Version,
+		Action:    tags.ActionPromote,
+	})
+	if recordErr != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", recordErr)
+	}
 	return
 }
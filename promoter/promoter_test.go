@@ -1,10 +1,15 @@
 package promoter
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/tags"
 	"github.com/screwdriver-cd/sd-cmd/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +33,22 @@ func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec,
 	}, nil
 }
 
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -51,6 +72,16 @@ func (d *dummySDAPI) RemoveTagCommand(spec *util.CommandSpec, tag string) (*util
 
 func (d *dummySDAPI) SetVerbose(isVerbose bool) {}
 
+// dummyGetErrorSDAPI fails to get the version which the tag points at
+type dummyGetErrorSDAPI struct {
+	dummySDAPI
+	err error
+}
+
+func (d *dummyGetErrorSDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	return nil, d.err
+}
+
 type dummyInvalidSDAPI struct{}
 
 func (d *dummyInvalidSDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
@@ -59,6 +90,22 @@ func (d *dummyInvalidSDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.Comma
 	}, nil
 }
 
+func (d *dummyInvalidSDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummyInvalidSDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -137,3 +184,61 @@ func TestRun(t *testing.T) {
 	err = p.Run()
 	assert.EqualError(t, err, "error")
 }
+
+func TestRunRecordsHistory(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_promoter")
+	defer os.RemoveAll(dir)
+	defer func() { config.TagHistoryPath = "" }()
+	config.TagHistoryPath = filepath.Join(dir, "history.jsonl")
+
+	sdapi := api.API(new(dummySDAPI))
+	p, _ := New(sdapi, []string{dummyCmdName, "1.0.1", dummyTag})
+	assert.Nil(t, p.Run())
+
+	moves, err := tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Nil(t, err)
+	if assert.Len(t, moves, 1) {
+		assert.Equal(t, tags.ActionPromote, moves[0].Action)
+		assert.Equal(t, dummyVersion, moves[0].From)
+		assert.Equal(t, dummyTargetVersion, moves[0].To)
+	}
+
+	// nothing is recorded if the promotion fails
+	p, _ = New(api.API(new(dummyInvalidSDAPI)), []string{dummyCmdName, "1.0.1", dummyTag})
+	assert.NotNil(t, p.Run())
+	moves, _ = tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Len(t, moves, 1)
+
+	// nothing is recorded if the previous version is unknown
+	p, _ = New(&dummyGetErrorSDAPI{err: errors.New("timeout")}, []string{dummyCmdName, "1.0.1", dummyTag})
+	assert.Nil(t, p.Run())
+	moves, _ = tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Len(t, moves, 1)
+
+	// the tag which does not exist is recorded without the previous version
+	p, _ = New(&dummyGetErrorSDAPI{err: &api.ResponseError{StatusCode: 404}}, []string{dummyCmdName, "1.0.1", dummyTag})
+	assert.Nil(t, p.Run())
+	moves, _ = tags.History(dummyNameSpace, dummyName, dummyTag)
+	if assert.Len(t, moves, 2) {
+		assert.Equal(t, "", moves[1].From)
+		assert.Equal(t, dummyTargetVersion, moves[1].To)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_promoter")
+	defer os.RemoveAll(dir)
+	defer func() { config.TagHistoryPath = "" }()
+	config.TagHistoryPath = filepath.Join(dir, "history.jsonl")
+
+	// TagCommand of dummyInvalidSDAPI fails if the request is sent
+	p, err := New(api.API(new(dummyInvalidSDAPI)), []string{"-dry-run", dummyCmdName, "1.0.1", dummyTag})
+	if assert.Nil(t, err) {
+		assert.True(t, p.isDryRun)
+		assert.Nil(t, p.Run())
+	}
+
+	// nothing is recorded on dry run
+	moves, _ := tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Empty(t, moves)
+}
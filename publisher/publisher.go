@@ -3,11 +3,19 @@ package publisher
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
+	"gopkg.in/yaml.v2"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/promoter"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
 	"github.com/screwdriver-cd/sd-cmd/util"
+	"github.com/screwdriver-cd/sd-cmd/validator"
 )
 
 // Publisher is a type to publish sdapi and sdstore.
@@ -18,11 +26,19 @@ type Publisher struct {
 	commandSpec *util.CommandSpec
 	sdAPI       api.API
 	tag         string
+	isDryRun    bool
+	// bump is the level to bump the latest published version at, or empty to publish the version in yaml
+	bump string
+	// tempDir has the archive built from a directory and the spec validated on dry run
+	tempDir string
 }
 
 func (p *Publisher) tagCommand(specResponse *util.CommandSpec) error {
-	commandFullName := path.Join(specResponse.Namespace, specResponse.Name)
-	promoter, err := promoter.New(p.sdAPI, []string{commandFullName, specResponse.Version, p.tag})
+	args := []string{path.Join(specResponse.Namespace, specResponse.Name), specResponse.Version, p.tag}
+	if p.isDryRun {
+		args = append([]string{"-dry-run"}, args...)
+	}
+	promoter, err := promoter.New(p.sdAPI, args)
 	if err != nil {
 		return err
 	}
@@ -30,8 +46,187 @@ func (p *Publisher) tagCommand(specResponse *util.CommandSpec) error {
 	return promoter.Run()
 }
 
+// bumpVersion returns MAJOR.MINOR of the next version of the latest published version of the command at the bump level.
+// Screwdriver API assigns the patch, so a patch bump publishes MAJOR.MINOR of the latest version.
+// The version in yaml is returned as it is if no version is published yet.
+func (p *Publisher) bumpVersion() (string, error) {
+	versions, err := p.sdAPI.GetCommandVersions(p.commandSpec)
+	if res, ok := err.(*api.ResponseError); ok && res.StatusCode == 404 {
+		versions, err = nil, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to get published versions: %v", err)
+	}
+
+	var latest *util.Version
+	for _, version := range versions {
+		v, err := util.ParseVersion(version)
+		if err != nil {
+			continue
+		}
+		if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return p.commandSpec.Version, nil
+	}
+
+	next, err := latest.Bump(p.bump)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d", next.Major, next.Minor), nil
+}
+
+// writeSpec writes the command spec to publish as yaml into the temporary directory, and returns its path
+func (p *Publisher) writeSpec() (string, error) {
+	data, err := yaml.Marshal(p.commandSpec)
+	if err != nil {
+		return "", fmt.Errorf("Failed to convert spec to yaml: %v", err)
+	}
+	if p.tempDir == "" {
+		p.tempDir, err = ioutil.TempDir("", "sd-cmd_publish")
+		if err != nil {
+			return "", fmt.Errorf("Failed to create temporary directory: %v", err)
+		}
+	}
+	specPath := filepath.Join(p.tempDir, "sd-command.yaml")
+	if err := ioutil.WriteFile(specPath, data, 0644); err != nil {
+		return "", fmt.Errorf("Failed to write spec: %v", err)
+	}
+	return specPath, nil
+}
+
+// prepareArtifact computes the sha256 digest of the file to publish and signs it
+// with the configured signing key. They are set to the command spec.
+// Each variant of a binary for multiple platforms is prepared respectively.
+// A remote habitat package has no file, so its package name is signed instead.
+// An archive is built from its directory unless the archive file is given.
+func (p *Publisher) prepareArtifact() error {
+	spec := p.commandSpec
+	switch {
+	case spec.Format == "binary" && spec.Binary != nil && len(spec.Binary.Platforms) > 0:
+		for _, variant := range spec.Binary.Platforms {
+			err := prepareFile(spec.SpecYamlPath, variant.File, &variant.Sha256, &variant.Signature)
+			if err != nil {
+				return fmt.Errorf("Failed to prepare the binary for %s: %v", variant.Platform(), err)
+			}
+		}
+		return nil
+	case spec.Format == "binary" && spec.Binary != nil:
+		return prepareFile(spec.SpecYamlPath, spec.Binary.File, &spec.Binary.Sha256, &spec.Binary.Signature)
+	case spec.Format == "habitat" && spec.Habitat != nil && spec.Habitat.Mode == "local":
+		return prepareFile(spec.SpecYamlPath, spec.Habitat.File, &spec.Habitat.Sha256, &spec.Habitat.Signature)
+	case spec.Format == "habitat" && spec.Habitat != nil:
+		return prepareData([]byte(spec.Habitat.Package), nil, &spec.Habitat.Signature)
+	case spec.Format == "archive" && spec.Archive != nil:
+		if spec.Archive.File == "" && spec.Archive.Directory != "" {
+			if err := p.buildArchive(); err != nil {
+				return err
+			}
+			return prepareFile(spec.SpecYamlPath, spec.Archive.Path, &spec.Archive.Sha256, &spec.Archive.Signature)
+		}
+		return prepareFile(spec.SpecYamlPath, spec.Archive.File, &spec.Archive.Sha256, &spec.Archive.Signature)
+	case spec.Format == "script" && spec.Script != nil:
+		return prepareFile(spec.SpecYamlPath, spec.Script.File, &spec.Script.Sha256, &spec.Script.Signature)
+	default:
+		return nil
+	}
+}
+
+// buildArchive builds a gzipped tarball from the directory of the archive into a temporary directory.
+// Only the file name of the tarball is published, its temporary path is kept for the upload.
+func (p *Publisher) buildArchive() error {
+	spec := p.commandSpec
+	tempDir, err := ioutil.TempDir("", "sd-cmd_publish")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary directory: %v", err)
+	}
+	p.tempDir = tempDir
+
+	archivePath := filepath.Join(tempDir, spec.Name+".tar.gz")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("Failed to create archive file: %v", err)
+	}
+	err = util.CreateTarGz(util.GetBinPath(spec.SpecYamlPath, spec.Archive.Directory), file)
+	closeError := file.Close()
+	if err != nil {
+		return err
+	}
+	if closeError != nil {
+		return fmt.Errorf("Failed to close archive file: %v", closeError)
+	}
+	spec.Archive.Path = archivePath
+	spec.Archive.File = filepath.Base(archivePath)
+	return nil
+}
+
+func prepareFile(specPath, filePath string, digest *string, signature **util.Signature) error {
+	data, err := util.LoadByte(util.GetBinPath(specPath, filePath))
+	if err != nil {
+		return err
+	}
+	return prepareData(data, digest, signature)
+}
+
+// prepareData sets the digest of data unless digest is nil, and the signature if a signing key is configured
+func prepareData(data []byte, digest *string, signature **util.Signature) error {
+	if digest != nil {
+		*digest = util.Sha256Sum(data)
+	}
+
+	if config.SigningKeyPath != "" {
+		key, err := util.LoadSigningKey(config.SigningKeyPath)
+		if err != nil {
+			return err
+		}
+		*signature = util.Sign(key, data)
+	}
+	return nil
+}
+
 // Run is a method to publish sdapi and sdstore.
 func (p *Publisher) Run() error {
+	defer func() {
+		if p.tempDir != "" {
+			os.RemoveAll(p.tempDir)
+		}
+	}()
+
+	if reasons := p.commandSpec.Args.Validate(); len(reasons) != 0 {
+		return fmt.Errorf("Invalid argument schema: %s", strings.Join(reasons, ", "))
+	}
+
+	if p.bump != "" {
+		version, err := p.bumpVersion()
+		if err != nil {
+			return fmt.Errorf("Bump failed: %v", err)
+		}
+		p.commandSpec.Version = version
+	}
+
+	err := p.prepareArtifact()
+	if err != nil {
+		return fmt.Errorf("Prepare failed: %v", err)
+	}
+
+	// the validator reads Screwdriver API only, so it is called on dry run with the spec to post
+	if p.isDryRun {
+		specPath, err := p.writeSpec()
+		if err != nil {
+			return err
+		}
+		val, err := validator.New(p.sdAPI, []string{"-f", specPath})
+		if err != nil {
+			return err
+		}
+		if err := val.Run(); err != nil {
+			return err
+		}
+	}
+
 	specResponse, err := p.sdAPI.PostCommand(p.commandSpec)
 	if err != nil {
 		return fmt.Errorf("Post failed: %v", err)
@@ -51,14 +246,17 @@ func (p *Publisher) Run() error {
 
 // New is a method to Generate new Publisher.
 // Publisher variable will be returned if input command and yaml file is valid.
-func New(api api.API, inputCommand []string) (p *Publisher, err error) {
+func New(sdAPI api.API, inputCommand []string) (p *Publisher, err error) {
 	p = new(Publisher)
 
-	p.sdAPI = api
-	p.specPath, p.tag, err = parsePublishCommand(inputCommand)
+	p.sdAPI = sdAPI
+	p.specPath, p.tag, p.bump, p.isDryRun, err = parsePublishCommand(inputCommand)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to parse command:%v", err)
 	}
+	if p.isDryRun {
+		p.sdAPI = api.DryRun(p.sdAPI, os.Stdout)
+	}
 
 	p.commandSpec, err = util.LoadYaml(p.specPath)
 	if err != nil {
@@ -69,15 +267,25 @@ func New(api api.API, inputCommand []string) (p *Publisher, err error) {
 	return
 }
 
-func parsePublishCommand(inputCommand []string) (yamlPath, tag string, err error) {
+func parsePublishCommand(inputCommand []string) (yamlPath, tag, bump string, isDryRun bool, err error) {
 	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
 	yamlPathAddr := fs.String("f", "sd-command.yaml", "Path of yaml to publish")
 	tagAddr := fs.String("t", "latest", "Tag name for your command")
+	bumpAddr := fs.String("bump", "", "Publish the next version of the latest published one (major, minor or patch)")
+	dryRunAddr := fs.Bool("dry-run", false, "Print the requests to Screwdriver API without sending them")
 
 	err = fs.Parse(inputCommand)
 	if err != nil {
-		return "", "", fmt.Errorf("Failed to parse input args:%v", err)
+		return "", "", "", false, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+
+	switch *bumpAddr {
+	case "", "major", "minor", "patch":
+	case "prerelease":
+		return "", "", "", false, fmt.Errorf("prerelease is invalid bump level, Screwdriver API publishes MAJOR.MINOR and assigns the patch, so a prerelease version can not be published")
+	default:
+		return "", "", "", false, fmt.Errorf("%q is invalid bump level, it must be one of major, minor, patch", *bumpAddr)
 	}
 
-	return *yamlPathAddr, *tagAddr, err
+	return *yamlPathAddr, *tagAddr, *bumpAddr, *dryRunAddr, err
 }
@@ -1,9 +1,17 @@
 package publisher
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
 	"github.com/screwdriver-cd/sd-cmd/util"
 	"github.com/stretchr/testify/assert"
@@ -30,24 +38,50 @@ const (
 
 const (
 	validSpecYamlPath   = "../testdata/yaml/binary-sd-command.yaml"
+	binarySpecYamlPath  = "../testdata/yaml/binary-relative-sd-command.yaml"
+	archiveSpecYamlPath = "../testdata/yaml/archive-sd-command.yaml"
+	scriptSpecYamlPath  = "../testdata/yaml/script-sd-command.yaml"
 	invalidSpecYamlPath = "../testdata/yaml/invalid_sd-command.yaml"
 )
 
 type dummySDAPI struct {
-	spec *util.CommandSpec
-	err  error
+	spec     *util.CommandSpec
+	err      error
+	versions []string
+	// posted is the spec of the last PostCommand
+	posted *util.CommandSpec
+	// validated is the yaml of the last ValidateCommand
+	validated string
 }
 
 func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return d.spec, d.err
 }
 
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return d.versions, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	d.posted = smallSpec
 	return d.spec, d.err
 }
 
 func (d *dummySDAPI) ValidateCommand(yamlString string) (*util.ValidateResponse, error) {
-	return nil, nil
+	d.validated = yamlString
+	return &util.ValidateResponse{}, nil
 }
 
 func (d *dummySDAPI) RemoveTagCommand(spec *util.CommandSpec, tag string) (*util.TagResponse, error) {
@@ -125,10 +159,115 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestPrepareArtifact(t *testing.T) {
+	sdapi := newDummySDAPI(dummyCommandSpec(binaryFormat), nil)
+	pub, err := New(sdapi, []string{"-f", binarySpecYamlPath})
+	assert.Nil(t, err)
+	assert.Nil(t, pub.prepareArtifact())
+	body, _ := util.LoadByte("../testdata/binary/hello")
+	assert.Equal(t, util.Sha256Sum(body), pub.commandSpec.Binary.Sha256)
+	assert.Nil(t, pub.commandSpec.Binary.Signature)
+
+	// success with signing
+	dir, _ := ioutil.TempDir("", "sd-cmd_publisher")
+	defer os.RemoveAll(dir)
+	pubKey, priv, _ := ed25519.GenerateKey(rand.Reader)
+	keyPath := filepath.Join(dir, "signing.key")
+	ioutil.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(priv.Seed())), 0600)
+	config.SigningKeyPath = keyPath
+	defer func() { config.SigningKeyPath = "" }()
+	assert.Nil(t, pub.prepareArtifact())
+	sig := pub.commandSpec.Binary.Signature
+	if assert.NotNil(t, sig) {
+		keys := map[string]ed25519.PublicKey{util.KeyID(pubKey): pubKey}
+		assert.Nil(t, util.VerifySignature(sig, body, keys))
+	}
+
+	// success with remote habitat
+	pub.commandSpec = dummyCommandSpec(habitatFormat)
+	assert.Nil(t, pub.prepareArtifact())
+	assert.NotNil(t, pub.commandSpec.Habitat.Signature)
+	assert.Equal(t, "", pub.commandSpec.Habitat.Sha256)
+
+	// success with binaries for multiple platforms
+	hart, _ := util.LoadByte("../testdata/binary/hello.hart")
+	pub.commandSpec = dummyCommandSpec(binaryFormat)
+	pub.commandSpec.SpecYamlPath = binarySpecYamlPath
+	pub.commandSpec.Binary = &util.Binary{
+		Platforms: []*util.Binary{
+			{OS: "linux", Arch: "amd64", File: "../binary/hello"},
+			{OS: "linux", Arch: "arm64", File: "../binary/hello.hart"},
+		},
+	}
+	assert.Nil(t, pub.prepareArtifact())
+	for i, data := range [][]byte{body, hart} {
+		variant := pub.commandSpec.Binary.Platforms[i]
+		assert.Equal(t, util.Sha256Sum(data), variant.Sha256)
+		if assert.NotNil(t, variant.Signature) {
+			keys := map[string]ed25519.PublicKey{util.KeyID(pubKey): pubKey}
+			assert.Nil(t, util.VerifySignature(variant.Signature, data, keys))
+		}
+	}
+	assert.Equal(t, "", pub.commandSpec.Binary.Sha256)
+
+	// failure. the binary file of a platform does not exist
+	pub.commandSpec.Binary.Platforms[1].File = "not_exist"
+	err = pub.prepareArtifact()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Failed to prepare the binary for linux/arm64")
+	}
+
+	// failure. the binary file does not exist
+	pub.commandSpec = dummyCommandSpec(binaryFormat)
+	pub.commandSpec.Binary.File = "not_exist"
+	assert.NotNil(t, pub.prepareArtifact())
+
+	// success with script
+	pub, err = New(sdapi, []string{"-f", scriptSpecYamlPath})
+	if assert.Nil(t, err) {
+		assert.Nil(t, pub.prepareArtifact())
+		script, _ := util.LoadByte("../testdata/script/hello.sh")
+		assert.Equal(t, util.Sha256Sum(script), pub.commandSpec.Script.Sha256)
+		assert.NotNil(t, pub.commandSpec.Script.Signature)
+	}
+}
+
+func TestPrepareArchive(t *testing.T) {
+	// success. the archive is built from the directory
+	pub, err := New(newDummySDAPI(dummyCommandSpec("archive"), nil), []string{"-f", archiveSpecYamlPath})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, pub.prepareArtifact())
+	defer os.RemoveAll(pub.tempDir)
+	archive := pub.commandSpec.Archive
+	assert.Equal(t, "bar.tar.gz", archive.File)
+	assert.Equal(t, filepath.Join(pub.tempDir, "bar.tar.gz"), archive.Path)
+	data, err := util.LoadByte(archive.Path)
+	if assert.Nil(t, err) {
+		assert.Equal(t, util.Sha256Sum(data), archive.Sha256)
+		dest, _ := ioutil.TempDir("", "sd-cmd_publisher")
+		defer os.RemoveAll(dest)
+		assert.Nil(t, util.ExtractArchive(data, dest))
+		hello, _ := util.LoadByte("../testdata/binary/hello")
+		extracted, _ := util.LoadByte(filepath.Join(dest, "hello"))
+		assert.Equal(t, hello, extracted)
+	}
+
+	// success. the archive file is given
+	pub.commandSpec.Archive = &util.Archive{File: archive.Path, Entrypoint: "hello"}
+	assert.Nil(t, pub.prepareArtifact())
+	assert.Equal(t, archive.Sha256, pub.commandSpec.Archive.Sha256)
+
+	// failure. the directory does not exist
+	pub.commandSpec.Archive = &util.Archive{Directory: "not_exist", Entrypoint: "hello"}
+	assert.NotNil(t, pub.prepareArtifact())
+}
+
 func TestRun(t *testing.T) {
 	spec := dummyCommandSpec(binaryFormat)
 	sdapi := newDummySDAPI(spec, nil)
-	pub, err := New(sdapi, []string{"-f", validSpecYamlPath})
+	pub, err := New(sdapi, []string{"-f", binarySpecYamlPath})
 	if err != nil {
 		t.Errorf("err=%v, want nil", err)
 	}
@@ -139,9 +278,90 @@ func TestRun(t *testing.T) {
 
 	// failure. failed to post command
 	sdapi = newDummySDAPI(spec, fmt.Errorf("failed to post command"))
-	pub, _ = New(sdapi, []string{"-f", validSpecYamlPath})
+	pub, _ = New(sdapi, []string{"-f", binarySpecYamlPath})
 	err = pub.Run()
 	if err == nil {
 		t.Errorf("err=nil, want error")
 	}
+
+	// failure. invalid argument schema
+	pub, _ = New(newDummySDAPI(spec, nil), []string{"-f", binarySpecYamlPath})
+	pub.commandSpec.Args = &util.ArgsSchema{Arguments: []*util.ArgSpec{{Name: "count", Type: "integer"}}}
+	assert.EqualError(t, pub.Run(), `Invalid argument schema: The type "integer" of the argument count is not one of string, int, number, bool`)
+}
+
+func TestRunDryRun(t *testing.T) {
+	// PostCommand of the dummy fails if the request is sent
+	spec := dummyCommandSpec(binaryFormat)
+	sdapi := newDummySDAPI(spec, fmt.Errorf("failed to post command"))
+	pub, err := New(sdapi, []string{"-dry-run", "-t", "stable", "-f", binarySpecYamlPath})
+	if err != nil {
+		t.Fatalf("err=%v, want nil", err)
+	}
+	assert.True(t, pub.isDryRun)
+
+	out := new(bytes.Buffer)
+	pub.sdAPI = api.DryRun(sdapi, out)
+	assert.Nil(t, pub.Run())
+
+	binary, _ := ioutil.ReadFile("../testdata/binary/hello")
+	assert.Contains(t, out.String(), "[dry-run] POST commands\n")
+	assert.Contains(t, out.String(), fmt.Sprintf(`part "file": file hello (%d bytes)`, len(binary)))
+	assert.Contains(t, out.String(), "[dry-run] PUT commands/foo/bar/tags/stable\n  Content-Type: application/json\n  {\"version\":\"1.0.0\"}\n")
+}
+
+func TestRunBump(t *testing.T) {
+	cases := []struct {
+		versions []string
+		bump     string
+		want     string
+	}{
+		// Screwdriver API assigns the patch
+		{[]string{"1.0.0", "1.10.2", "1.9.5", "latest"}, "patch", "1.10"},
+		{[]string{"1.0.0", "1.10.2"}, "minor", "1.11"},
+		{[]string{"1.0.0", "1.10.2"}, "major", "2.0"},
+		{[]string{"1.10.2", "2.0.0-beta.1"}, "major", "2.0"},
+		// the version in yaml is published if no version is published yet
+		{nil, "patch", "1.0"},
+	}
+	for _, c := range cases {
+		d := &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: c.versions}
+		pub, err := New(d, []string{"-bump", c.bump, "-f", binarySpecYamlPath})
+		if err != nil {
+			t.Fatalf("err=%v, want nil", err)
+		}
+		assert.Nil(t, pub.Run())
+		if assert.NotNil(t, d.posted) {
+			assert.Equal(t, c.want, d.posted.Version, "%v by %s", c.versions, c.bump)
+		}
+	}
+
+	// the yaml is not rewritten
+	spec, _ := util.LoadYaml(binarySpecYamlPath)
+	assert.Equal(t, "1.0", spec.Version)
+
+	// failure. invalid bump level
+	_, err := New(newDummySDAPI(nil, nil), []string{"-bump", "build", "-f", binarySpecYamlPath})
+	assert.EqualError(t, err, `Failed to parse command:"build" is invalid bump level, it must be one of major, minor, patch`)
+	_, err = New(newDummySDAPI(nil, nil), []string{"-bump", "prerelease", "-f", binarySpecYamlPath})
+	assert.EqualError(t, err, "Failed to parse command:prerelease is invalid bump level, Screwdriver API publishes MAJOR.MINOR and assigns the patch, so a prerelease version can not be published")
+}
+
+func TestRunBumpDryRun(t *testing.T) {
+	// stable points at the latest version
+	d := &dummySDAPI{spec: dummyCommandSpec(binaryFormat), versions: []string{"1.0.0", "1.10.2", "1.9.5"}}
+	d.spec.Version = "1.10.2"
+	pub, err := New(d, []string{"-dry-run", "-bump", "patch", "-t", "stable", "-f", binarySpecYamlPath})
+	if err != nil {
+		t.Fatalf("err=%v, want nil", err)
+	}
+	out := new(bytes.Buffer)
+	pub.sdAPI = api.DryRun(d, out)
+	assert.Nil(t, pub.Run())
+
+	// the bumped spec is validated, and the version assigned by Screwdriver API is tagged
+	assert.Contains(t, d.validated, `version: "1.10"`)
+	assert.Contains(t, d.validated, "sha256: ")
+	assert.Nil(t, d.posted)
+	assert.Contains(t, out.String(), "[dry-run] PUT commands/foo/bar/tags/stable\n  Content-Type: application/json\n  {\"version\":\"1.10.3\"}\n")
 }
@@ -1,10 +1,13 @@
 package removeTag
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/tags"
 	"github.com/screwdriver-cd/sd-cmd/util"
 )
 
@@ -13,11 +16,22 @@ type RemoveTag struct {
 	smallSpec *util.CommandSpec
 	sdAPI     api.API
 	tag       string
+	isDryRun  bool
 }
 
 // New generates new removeTag.
-// args is expected as ["namespace/name", "tag"]
-func New(api api.API, args []string) (p *RemoveTag, err error) {
+// args is expected as ["namespace/name", "tag"] with optional flags before them
+func New(sdAPI api.API, args []string) (p *RemoveTag, err error) {
+	fs := flag.NewFlagSet("removeTag", flag.ContinueOnError)
+	isDryRun := fs.Bool("dry-run", false, "Print the requests to Screwdriver API without sending them")
+	if err = fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	if *isDryRun {
+		sdAPI = api.DryRun(sdAPI, os.Stdout)
+	}
+
+	args = fs.Args()
 	if len(args) != 2 {
 		return nil, fmt.Errorf("parameters are not enough")
 	}
@@ -40,7 +54,8 @@ func New(api api.API, args []string) (p *RemoveTag, err error) {
 
 	p = &RemoveTag{
 		smallSpec: smallSpec,
-		sdAPI:     api,
+		sdAPI:     sdAPI,
+		isDryRun:  *isDryRun,
 		tag:       tag,
 	}
 
@@ -49,7 +64,7 @@ func New(api api.API, args []string) (p *RemoveTag, err error) {
 
 // Run executes tag command API
 func (p *RemoveTag) Run() (err error) {
-	_, err = p.sdAPI.GetCommand(p.smallSpec)
+	spec, err := p.sdAPI.GetCommand(p.smallSpec)
 	if err != nil {
 		fmt.Printf("%v does not exist yet\n", p.tag)
 		err = nil
@@ -63,5 +78,22 @@ func (p *RemoveTag) Run() (err error) {
 	}
 
 	fmt.Printf("Removing %v from %v\n", res.Tag, res.Version)
+
+	// the tag is not moved on dry run, so nothing is recorded
+	if p.isDryRun {
+		return
+	}
+
+	// the removal succeeded, so failing to record it is only warned
+	recordErr := tags.Record(&tags.Move{
+		Namespace: p.smallSpec.Namespace,
+		Name:      p.smallSpec.Name,
+		Tag:       p.tag,
+		From:      spec.Version,
+		Action:    tags.ActionRemove,
+	})
+	if recordErr != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", recordErr)
+	}
 	return
 }
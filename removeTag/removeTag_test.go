@@ -1,10 +1,15 @@
 package removeTag
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/tags"
 	"github.com/screwdriver-cd/sd-cmd/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,10 +31,26 @@ func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec,
 	if smallSpec.Version == nonexistentTag {
 		return nil, errors.New("error")
 	} else {
-		return &util.CommandSpec{}, nil
+		return &util.CommandSpec{Version: dummyVersion}, nil
 	}
 }
 
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -63,6 +84,22 @@ func (d *dummyInvalidSDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.Comma
 	return &util.CommandSpec{}, nil
 }
 
+func (d *dummyInvalidSDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummyInvalidSDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummyInvalidSDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -138,3 +175,39 @@ func TestRun(t *testing.T) {
 	err = p.Run()
 	assert.EqualError(t, err, "error")
 }
+
+func TestRunRecordsHistory(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_removeTag")
+	defer os.RemoveAll(dir)
+	defer func() { config.TagHistoryPath = "" }()
+	config.TagHistoryPath = filepath.Join(dir, "history.jsonl")
+
+	p, _ := New(api.API(new(dummySDAPI)), []string{dummyCmdName, dummyTag})
+	assert.Nil(t, p.Run())
+
+	moves, err := tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Nil(t, err)
+	if assert.Len(t, moves, 1) {
+		assert.Equal(t, tags.ActionRemove, moves[0].Action)
+		assert.Equal(t, dummyVersion, moves[0].From)
+		assert.Equal(t, "", moves[0].To)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_removeTag")
+	defer os.RemoveAll(dir)
+	defer func() { config.TagHistoryPath = "" }()
+	config.TagHistoryPath = filepath.Join(dir, "history.jsonl")
+
+	// RemoveTagCommand of dummyInvalidSDAPI fails if the request is sent
+	p, err := New(api.API(new(dummyInvalidSDAPI)), []string{"-dry-run", dummyCmdName, dummyTag})
+	if assert.Nil(t, err) {
+		assert.True(t, p.isDryRun)
+		assert.Nil(t, p.Run())
+	}
+
+	// nothing is recorded on dry run
+	moves, _ := tags.History(dummyNameSpace, dummyName, dummyTag)
+	assert.Empty(t, moves)
+}
@@ -10,6 +10,8 @@ import (
 	"net/url"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"time"
 
 	retryhttp "github.com/hashicorp/go-retryablehttp"
@@ -24,6 +26,10 @@ const (
 // API is a Screwdriver API endpoint
 type API interface {
 	GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error)
+	GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error)
+	GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error)
+	ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error)
+	SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error)
 	PostCommand(commandSpec *util.CommandSpec) (*util.CommandSpec, error)
 	ValidateCommand(yamlString string) (*util.ValidateResponse, error)
 	TagCommand(commandSpec *util.CommandSpec, targetVersion, tag string) (*util.TagResponse, error)
@@ -103,6 +109,127 @@ func (c client) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, erro
 	return responseSpec, nil
 }
 
+// getJSON sends a GET request to uri and parses the response into v
+func (c client) getJSON(uri string, v interface{}) error {
+	// No payload
+	payload := new(bytes.Buffer)
+
+	responseBytes, statusCode, err := c.sendHTTPRequest("GET", uri, defaultContentType, payload)
+	if err != nil {
+		return fmt.Errorf("Get request failed: %v", err)
+	}
+
+	responseBytes, err = handleResponse(responseBytes, statusCode)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(responseBytes, v)
+	if err != nil {
+		return fmt.Errorf("Failed to parse json response %v", err)
+	}
+	return nil
+}
+
+// GetCommandVersions returns all published versions of the command from Screwdriver API
+func (c client) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	uri, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URL on GET: %v", err)
+	}
+	uri.Path = path.Join(uri.Path, "commands", smallSpec.Namespace, smallSpec.Name)
+
+	var specs []util.CommandSpec
+	if err := c.getJSON(uri.String(), &specs); err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		versions = append(versions, spec.Version)
+	}
+	return versions, nil
+}
+
+// GetCommandVersionDetails returns all published versions of the command with their publish time
+// and the tags pointing at them from Screwdriver API
+func (c client) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	uri, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URL on GET: %v", err)
+	}
+	uri.Path = path.Join(uri.Path, "commands", smallSpec.Namespace, smallSpec.Name)
+
+	var specs []struct {
+		Version    string    `json:"version"`
+		CreateTime time.Time `json:"createTime"`
+	}
+	if err := c.getJSON(uri.String(), &specs); err != nil {
+		return nil, err
+	}
+
+	uri.Path = path.Join(uri.Path, "tags")
+	var tags []util.TagResponse
+	if err := c.getJSON(uri.String(), &tags); err != nil {
+		return nil, err
+	}
+
+	versions := make([]*util.CommandVersion, 0, len(specs))
+	byVersion := make(map[string]*util.CommandVersion, len(specs))
+	for _, spec := range specs {
+		version := &util.CommandVersion{Version: spec.Version, CreateTime: spec.CreateTime, Tags: []string{}}
+		versions = append(versions, version)
+		byVersion[spec.Version] = version
+	}
+	for _, tag := range tags {
+		if version, ok := byVersion[tag.Version]; ok {
+			version.Tags = append(version.Tags, tag.Tag)
+		}
+	}
+	for _, version := range versions {
+		sort.Strings(version.Tags)
+	}
+	return versions, nil
+}
+
+// ListCommands returns the published commands from Screwdriver API.
+// They are filtered by namespace unless it is empty. page and count paginate them unless they are 0.
+func (c client) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	return c.listCommands(query, page, count)
+}
+
+// SearchCommands returns the published commands whose namespace, name or description contain keyword from Screwdriver API
+func (c client) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	query := url.Values{}
+	query.Set("search", keyword)
+	return c.listCommands(query, page, count)
+}
+
+func (c client) listCommands(query url.Values, page, count int) ([]*util.CommandSpec, error) {
+	uri, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URL on GET: %v", err)
+	}
+	uri.Path = path.Join(uri.Path, "commands")
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if count > 0 {
+		query.Set("count", strconv.Itoa(count))
+	}
+	uri.RawQuery = query.Encode()
+
+	var specs []*util.CommandSpec
+	if err := c.getJSON(uri.String(), &specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
 func writeValidateBody(yamlString string) (bodyBuff *bytes.Buffer, err error) {
 	var payload util.PayloadYaml
 	payload.Yaml = yamlString
@@ -160,33 +287,18 @@ func writeMultipartYaml(writer *multipart.Writer, commandSpec *util.CommandSpec)
 	return nil
 }
 
-func getBinPath(specPath string, filePath string) string {
-	if path.IsAbs(filePath) {
-		return filePath
-	}
-	return filepath.Join(filepath.Dir(specPath), filePath)
-}
-
-func writeMultipartBin(writer *multipart.Writer, commandSpec *util.CommandSpec) error {
-	var filePath string
-	switch commandSpec.Format {
-	case "binary":
-		filePath = commandSpec.Binary.File
-	case "habitat":
-		filePath = commandSpec.Habitat.File
-	}
-
+func writeMultipartFile(writer *multipart.Writer, fieldName, specPath, filePath string) error {
 	// normalize the binary file path as a relative path from the spec yaml.
-	filePath = getBinPath(commandSpec.SpecYamlPath, filePath)
+	filePath = util.GetBinPath(specPath, filePath)
 	fileContents, err := util.LoadByte(filePath)
 	if err != nil {
 		return fmt.Errorf("Failed to load file:%v", err)
 	}
 
 	fileName := filepath.Base(filePath)
-	filePart, err := writer.CreateFormFile("file", fileName)
+	filePart, err := writer.CreateFormFile(fieldName, fileName)
 	if err != nil {
-		return fmt.Errorf("Failed to create form of %s:%v", commandSpec.Format, err)
+		return fmt.Errorf("Failed to create form of %s:%v", fieldName, err)
 	}
 
 	_, err = filePart.Write(fileContents)
@@ -197,6 +309,37 @@ func writeMultipartBin(writer *multipart.Writer, commandSpec *util.CommandSpec)
 	return nil
 }
 
+// writeMultipartBin writes the file of the command.
+// A binary for multiple platforms is written as a "file-<os>-<arch>" part per platform.
+func writeMultipartBin(writer *multipart.Writer, commandSpec *util.CommandSpec) error {
+	var filePath string
+	switch commandSpec.Format {
+	case "binary":
+		if len(commandSpec.Binary.Platforms) > 0 {
+			for _, variant := range commandSpec.Binary.Platforms {
+				fieldName := fmt.Sprintf("file-%s-%s", variant.OS, variant.Arch)
+				err := writeMultipartFile(writer, fieldName, commandSpec.SpecYamlPath, variant.File)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		filePath = commandSpec.Binary.File
+	case "habitat":
+		filePath = commandSpec.Habitat.File
+	case "archive":
+		filePath = commandSpec.Archive.File
+		if commandSpec.Archive.Path != "" {
+			filePath = commandSpec.Archive.Path
+		}
+	case "script":
+		filePath = commandSpec.Script.File
+	}
+
+	return writeMultipartFile(writer, "file", commandSpec.SpecYamlPath, filePath)
+}
+
 func (c client) httpRequest(httpMethod string, uri string, contentType string, body *bytes.Buffer) (*util.CommandSpec, error) {
 	responseBytes, statusCode, err := c.sendHTTPRequest(httpMethod, uri, contentType, body)
 	if err != nil {
@@ -237,14 +380,10 @@ func writeMultipart(commandSpec *util.CommandSpec) (*bytes.Buffer, string, error
 	return body, contentType, nil
 }
 
-func (c client) PostCommand(commandSpec *util.CommandSpec) (*util.CommandSpec, error) {
-	var (
-		body        *bytes.Buffer
-		contentType string
-		err         error
-	)
+// commandPayload returns the body to post the command spec and its content type
+func commandPayload(commandSpec *util.CommandSpec) (body *bytes.Buffer, contentType string, err error) {
 	switch commandSpec.Format {
-	case "binary":
+	case "binary", "archive", "script":
 		body, contentType, err = writeMultipart(commandSpec)
 	case "habitat":
 		if commandSpec.Habitat.Mode == "local" {
@@ -257,8 +396,13 @@ func (c client) PostCommand(commandSpec *util.CommandSpec) (*util.CommandSpec, e
 		body, err = specToPayloadBuf(commandSpec)
 		contentType = defaultContentType
 	default:
-		return nil, fmt.Errorf(`Unknown "Format" value of command spec: %v`, err)
+		return nil, "", fmt.Errorf(`Unknown "Format" value of command spec: %v`, err)
 	}
+	return
+}
+
+func (c client) PostCommand(commandSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	body, contentType, err := commandPayload(commandSpec)
 	if err != nil {
 		return nil, err
 	}
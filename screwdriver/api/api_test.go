@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"mime"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	retryhttp "github.com/hashicorp/go-retryablehttp"
 	"github.com/screwdriver-cd/sd-cmd/util"
@@ -157,6 +161,179 @@ func TestGetCommand(t *testing.T) {
 	}
 }
 
+func TestGetCommandVersions(t *testing.T) {
+	c := newClient(fakeAPIURL, fakeSDToken)
+	// case success
+	smallSpec := dummySmallSpec()
+	jsonMsg := fmt.Sprintf(`[{"namespace":"%s","name":"%s","version":"1.0.1"},{"namespace":"%s","name":"%s","version":"1.0.0"}]`,
+		smallSpec.Namespace, smallSpec.Name, smallSpec.Namespace, smallSpec.Name)
+	c.client = makeFakeHTTPClient(t, 200, jsonMsg, fmt.Sprintf("/v4/commands/%s/%s", smallSpec.Namespace, smallSpec.Name))
+	api := API(c)
+
+	// request
+	versions, err := api.GetCommandVersions(smallSpec)
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	}
+	if len(versions) != 2 || versions[0] != "1.0.1" || versions[1] != "1.0.0" {
+		t.Errorf("versions=%q, want %q", versions, []string{"1.0.1", "1.0.0"})
+	}
+
+	// case failure. check 4xx error message
+	errMsg := `{"statusCode": 404,"error": "Not Found","message": "Command does not exist"}`
+	ansMsg := "Screwdriver API 404 Not Found: Command does not exist"
+	c.client = makeFakeHTTPClient(t, 404, errMsg, "")
+	api = API(c)
+
+	// request
+	_, err = api.GetCommandVersions(smallSpec)
+	if err == nil || err.Error() != ansMsg {
+		t.Errorf("err=%v, want %q", err, ansMsg)
+	}
+
+	// case failure. check some api response error
+	for _, res := range errorResponses {
+		c.client = makeFakeHTTPClient(t, res.code, res.message, "")
+		api = API(c)
+		_, err = api.GetCommandVersions(smallSpec)
+		if err == nil {
+			t.Errorf("err=nil, want error")
+		}
+	}
+}
+
+func TestGetCommandVersionDetails(t *testing.T) {
+	smallSpec := dummySmallSpec()
+	versionsPath := fmt.Sprintf("/v4/commands/%s/%s", smallSpec.Namespace, smallSpec.Name)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case versionsPath:
+			fmt.Fprintf(w, `[{"version":"1.0.1","createTime":"2020-01-02T03:04:05.000Z"},{"version":"1.0.0"}]`)
+		case versionsPath + "/tags":
+			fmt.Fprintf(w, `[{"tag":"stable","version":"1.0.1"},{"tag":"latest","version":"1.0.1"},{"tag":"old","version":"0.9.0"}]`)
+		default:
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"statusCode": 404,"error": "Not Found","message": "Not Found"}`)
+		}
+	}))
+	defer server.Close()
+	c := newClient(server.URL+"/v4/", fakeSDToken)
+	api := API(c)
+
+	// case success
+	versions, err := api.GetCommandVersionDetails(smallSpec)
+	assert.Nil(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, "1.0.1", versions[0].Version)
+		assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), versions[0].CreateTime.UTC())
+		assert.Equal(t, []string{"latest", "stable"}, versions[0].Tags)
+		assert.True(t, versions[1].CreateTime.IsZero())
+		assert.Empty(t, versions[1].Tags)
+	}
+
+	// case failure. check 4xx error message
+	_, err = api.GetCommandVersionDetails(&util.CommandSpec{Namespace: "foo", Name: "bar"})
+	assert.EqualError(t, err, "Screwdriver API 404 Not Found: Not Found")
+
+	// case failure. broken json
+	c.client = makeFakeHTTPClient(t, 200, "[{broken", "")
+	api = API(c)
+	_, err = api.GetCommandVersionDetails(smallSpec)
+	assert.NotNil(t, err)
+}
+
+// makeFakeHTTPClientWithQuery is makeFakeHTTPClient which also records the path and the query of the last request
+func makeFakeHTTPClientWithQuery(t *testing.T, code int, body string, reqPath *string, query *url.Values) *retryhttp.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*reqPath = r.URL.Path
+		*query = r.URL.Query()
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	tr := &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(server.URL)
+		},
+	}
+
+	client := retryhttp.NewClient()
+	client.HTTPClient.Transport = tr
+	return client
+}
+
+func TestListCommands(t *testing.T) {
+	c := newClient(fakeAPIURL, fakeSDToken)
+	var (
+		reqPath string
+		query   url.Values
+	)
+	// case success
+	jsonMsg := fmt.Sprintf(`[{"namespace":"%s","name":"%s","version":"1.0.1","format":"binary"},{"namespace":"%s","name":"bar","version":"2.0.0","format":"docker"}]`,
+		dummyNamespace, dummyName, dummyNamespace)
+	c.client = makeFakeHTTPClientWithQuery(t, 200, jsonMsg, &reqPath, &query)
+	api := API(c)
+
+	specs, err := api.ListCommands(dummyNamespace, 2, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "/v4/commands", reqPath)
+	assert.Equal(t, url.Values{"namespace": {dummyNamespace}, "page": {"2"}, "count": {"10"}}, query)
+	if assert.Len(t, specs, 2) {
+		assert.Equal(t, dummyName, specs[0].Name)
+		assert.Equal(t, "docker", specs[1].Format)
+	}
+
+	// all namespaces without pagination
+	_, err = api.ListCommands("", 0, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, query)
+
+	// case failure. check 4xx error message
+	errMsg := `{"statusCode": 403,"error": "Forbidden","message": "Access Denied"}`
+	c.client = makeFakeHTTPClient(t, 403, errMsg, "")
+	api = API(c)
+	_, err = api.ListCommands(dummyNamespace, 1, 10)
+	assert.EqualError(t, err, "Screwdriver API 403 Forbidden: Access Denied")
+
+	// case failure. check some api response error
+	for _, res := range errorResponses {
+		c.client = makeFakeHTTPClient(t, res.code, res.message, "")
+		api = API(c)
+		_, err = api.ListCommands(dummyNamespace, 1, 10)
+		if err == nil {
+			t.Errorf("err=nil, want error")
+		}
+	}
+}
+
+func TestSearchCommands(t *testing.T) {
+	c := newClient(fakeAPIURL, fakeSDToken)
+	var (
+		reqPath string
+		query   url.Values
+	)
+	// case success
+	jsonMsg := fmt.Sprintf(`[{"namespace":"%s","name":"%s","version":"1.0.1","description":"%s"}]`,
+		dummyNamespace, dummyName, dummyDescription)
+	c.client = makeFakeHTTPClientWithQuery(t, 200, jsonMsg, &reqPath, &query)
+	api := API(c)
+
+	specs, err := api.SearchCommands("dummy desc", 1, 50)
+	assert.Nil(t, err)
+	assert.Equal(t, "/v4/commands", reqPath)
+	assert.Equal(t, url.Values{"search": {"dummy desc"}, "page": {"1"}, "count": {"50"}}, query)
+	if assert.Len(t, specs, 1) {
+		assert.Equal(t, dummyDescription, specs[0].Description)
+	}
+
+	// case failure. check 4xx error message
+	errMsg := `{"statusCode": 404,"error": "Not Found","message": "Not Found"}`
+	c.client = makeFakeHTTPClient(t, 404, errMsg, "")
+	api = API(c)
+	_, err = api.SearchCommands("dummy", 1, 50)
+	assert.EqualError(t, err, "Screwdriver API 404 Not Found: Not Found")
+}
+
 // TestRetryCommand - makes three attempts to make a http request
 // the first two will fail and the final one will succeed
 func TestRetryCommand(t *testing.T) {
@@ -173,25 +350,6 @@ func TestRetryCommand(t *testing.T) {
 	}
 }
 
-func TestGetBinPath(t *testing.T) {
-	testCases := []struct {
-		specPath     string
-		filePath     string
-		expectedPath string
-	}{
-		{"sd-command.yaml", "hello", "hello"},
-		{"sd-command.yaml", "./hello", "hello"},
-		// Note: allow an absolute path.
-		{"sd-command.yaml", "/usr/local/bin/hello", "/usr/local/bin/hello"},
-		{"./sd-command.yaml", "hello", "hello"},
-		{"../../testdata/yaml/sd-command.yaml", "bin/hello", "../../testdata/yaml/bin/hello"},
-	}
-
-	for _, tc := range testCases {
-		assert.Equal(t, tc.expectedPath, getBinPath(tc.specPath, tc.filePath))
-	}
-}
-
 func TestSendHTTPRequest(t *testing.T) {
 	ns, name, ver := "foo", "bar", "1.0"
 	jsonResponse := fmt.Sprintf(`{"namespace":"%s","name":"%s","version":"%s","format":"binary","binary":{"file":"./foobar.sh"}}`, ns, name, ver)
@@ -279,6 +437,34 @@ func TestPostCommand(t *testing.T) {
 		t.Errorf("err=%q, want nil", err)
 	}
 
+	// case success archive
+	spec = dummySpec("archive")
+	spec.Archive = &util.Archive{File: binaryFilePath, Entrypoint: "hello"}
+	responseMsg = fmt.Sprintf(`{"id":76,"namespace":"%s","name":"%s",
+		"version":"%s","description":"foobar","maintainer":"foo@yahoo-corp.jp",
+		"format":"archive","archive":{"file":"%s","entrypoint":"hello"},"pipelineId":250270}`,
+		spec.Namespace, spec.Name, spec.Version, spec.Archive.File)
+	c.client = makeFakeHTTPClient(t, 200, responseMsg, "/v4/commands")
+	api = API(c)
+	_, err = api.PostCommand(spec)
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	}
+
+	// case success script
+	spec = dummySpec("script")
+	spec.Script = &util.Script{File: binaryFilePath, Interpreter: "sh"}
+	responseMsg = fmt.Sprintf(`{"id":76,"namespace":"%s","name":"%s",
+		"version":"%s","description":"foobar","maintainer":"foo@yahoo-corp.jp",
+		"format":"script","script":{"file":"%s","interpreter":"sh"},"pipelineId":250270}`,
+		spec.Namespace, spec.Name, spec.Version, spec.Script.File)
+	c.client = makeFakeHTTPClient(t, 200, responseMsg, "/v4/commands")
+	api = API(c)
+	_, err = api.PostCommand(spec)
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	}
+
 	// case success docker
 	spec = dummySpec(dockerFormat)
 	responseMsg = fmt.Sprintf(`{"id":76,"namespace":"%s","name":"%s",
@@ -356,6 +542,71 @@ func TestPostCommand(t *testing.T) {
 	}
 }
 
+func TestWriteMultipartPlatforms(t *testing.T) {
+	spec := dummySpec(binaryFormat)
+	spec.Binary = &util.Binary{
+		Platforms: []*util.Binary{
+			{OS: "linux", Arch: "amd64", File: binaryFilePath},
+			{OS: "linux", Arch: "arm64", File: habitatPackagePath},
+		},
+	}
+
+	body, contentType, err := writeMultipart(spec)
+	if err != nil {
+		t.Fatalf("err=%q, want nil", err)
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+	reader := multipart.NewReader(body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		parts = append(parts, part.FormName()+":"+part.FileName())
+	}
+	expected := []string{"spec:", "file-linux-amd64:hello", "file-linux-arm64:hello.hart"}
+	if fmt.Sprint(parts) != fmt.Sprint(expected) {
+		t.Errorf("parts=%q, want %q", parts, expected)
+	}
+
+	// failure. the file of a platform does not exist
+	spec.Binary.Platforms[1].File = "not_exist"
+	if _, _, err = writeMultipart(spec); err == nil {
+		t.Errorf("err=nil, want error")
+	}
+}
+
+func TestWriteMultipartBuiltArchive(t *testing.T) {
+	spec := dummySpec("archive")
+	spec.Archive = &util.Archive{File: "bar.tar.gz", Path: binaryFilePath, Entrypoint: "hello"}
+
+	body, contentType, err := writeMultipart(spec)
+	if err != nil {
+		t.Fatalf("err=%q, want nil", err)
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		content, _ := ioutil.ReadAll(part)
+		switch part.FormName() {
+		case "spec":
+			// the built archive is published with its file name only
+			if strings.Contains(string(content), binaryFilePath) || !strings.Contains(string(content), `"file":"bar.tar.gz"`) {
+				t.Errorf("spec=%q, want the file bar.tar.gz", content)
+			}
+		case "file":
+			if part.FileName() != "hello" {
+				t.Errorf("file=%q, want hello", part.FileName())
+			}
+		}
+	}
+}
+
 func TestValidateCommand(t *testing.T) {
 	// case success
 	c := newClient(fakeAPIURL, fakeSDToken)
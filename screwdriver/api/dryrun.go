@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// dryRun is API which sends the requests reading Screwdriver API only.
+// The requests changing Screwdriver API are printed with their payloads instead of being sent.
+type dryRun struct {
+	API
+	baseURL string
+	out     io.Writer
+}
+
+// DryRun returns API which prints the requests changing Screwdriver API to out instead of sending them
+func DryRun(sdAPI API, out io.Writer) API {
+	if d, ok := sdAPI.(*dryRun); ok {
+		return d
+	}
+	d := &dryRun{API: sdAPI, out: out}
+	if c, ok := sdAPI.(*client); ok {
+		d.baseURL = c.baseURL
+	}
+	return API(d)
+}
+
+// endpoint returns the URL of the elements joined to the base URL
+func (d *dryRun) endpoint(elem ...string) string {
+	uri, err := url.Parse(d.baseURL)
+	if err != nil {
+		return path.Join(elem...)
+	}
+	uri.Path = path.Join(append([]string{uri.Path}, elem...)...)
+	return uri.String()
+}
+
+// printRequest prints the request and its payload.
+// Each part of a multipart payload is printed with the file name and the size of its content.
+func (d *dryRun) printRequest(method, uri, contentType string, body *bytes.Buffer) error {
+	fmt.Fprintf(d.out, "[dry-run] %s %s\n", method, uri)
+	if body == nil || body.Len() == 0 {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		fmt.Fprintf(d.out, "  Content-Type: %s\n  %s\n", contentType, body.String())
+		return nil
+	}
+
+	fmt.Fprintf(d.out, "  Content-Type: %s\n", mediaType)
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read multipart payload: %v", err)
+		}
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("Failed to read multipart payload: %v", err)
+		}
+		if part.FileName() == "" {
+			fmt.Fprintf(d.out, "  part %q: %s\n", part.FormName(), content)
+		} else {
+			fmt.Fprintf(d.out, "  part %q: file %s (%d bytes)\n", part.FormName(), part.FileName(), len(content))
+		}
+	}
+}
+
+// nextVersion returns the version which Screwdriver API assigns to the command published with version MAJOR.MINOR.
+// The patch is the next of the highest published one.
+func nextVersion(version string, published []string) string {
+	if strings.Count(version, ".") != 1 {
+		return version
+	}
+	patch := 0
+	for _, v := range published {
+		if !strings.HasPrefix(v, version+".") {
+			continue
+		}
+		if p, err := strconv.Atoi(strings.TrimPrefix(v, version+".")); err == nil && p >= patch {
+			patch = p + 1
+		}
+	}
+	return fmt.Sprintf("%s.%d", version, patch)
+}
+
+// PostCommand prints the request to publish the command, and returns the spec with the version which would be assigned
+func (d *dryRun) PostCommand(commandSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	body, contentType, err := commandPayload(commandSpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.printRequest("POST", d.endpoint("commands"), contentType, body); err != nil {
+		return nil, err
+	}
+
+	// the command may be published for the first time, so failing to get the versions means no version
+	published, _ := d.API.GetCommandVersions(commandSpec)
+	spec := *commandSpec
+	spec.Version = nextVersion(commandSpec.Version, published)
+	return &spec, nil
+}
+
+// TagCommand prints the request to move the tag to the target version
+func (d *dryRun) TagCommand(commandSpec *util.CommandSpec, targetVersion, tag string) (*util.TagResponse, error) {
+	body, err := versionToPayLoadBuf(targetVersion)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create payload: %v", err)
+	}
+	uri := d.endpoint("commands", commandSpec.Namespace, commandSpec.Name, "tags", tag)
+	if err := d.printRequest("PUT", uri, defaultContentType, body); err != nil {
+		return nil, err
+	}
+
+	// the target version can be a tag, which Screwdriver API resolves
+	version := targetVersion
+	target := &util.CommandSpec{Namespace: commandSpec.Namespace, Name: commandSpec.Name, Version: targetVersion}
+	if spec, err := d.API.GetCommand(target); err == nil {
+		version = spec.Version
+	}
+	return &util.TagResponse{Namespace: commandSpec.Namespace, Name: commandSpec.Name, Tag: tag, Version: version}, nil
+}
+
+// RemoveTagCommand prints the request to remove the tag
+func (d *dryRun) RemoveTagCommand(commandSpec *util.CommandSpec, tag string) (*util.TagResponse, error) {
+	uri := d.endpoint("commands", commandSpec.Namespace, commandSpec.Name, "tags", tag)
+	if err := d.printRequest("DELETE", uri, defaultContentType, nil); err != nil {
+		return nil, err
+	}
+
+	version := ""
+	target := &util.CommandSpec{Namespace: commandSpec.Namespace, Name: commandSpec.Name, Version: tag}
+	if spec, err := d.API.GetCommand(target); err == nil {
+		version = spec.Version
+	}
+	return &util.TagResponse{Namespace: commandSpec.Namespace, Name: commandSpec.Name, Tag: tag, Version: version}, nil
+}
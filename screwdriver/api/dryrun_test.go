@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// newDryRunServer returns a server which has the versions of the dummy command and fails the test on the requests changing it
+func newDryRunServer(t *testing.T) *httptest.Server {
+	commandPath := fmt.Sprintf("/v4/commands/%s/%s", dummyNamespace, dummyName)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("%s %s is sent on dry run", r.Method, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case commandPath:
+			fmt.Fprint(w, `[{"version":"1.0.0"},{"version":"1.0.3"},{"version":"1.1.0"},{"version":"2.0.0"}]`)
+		case commandPath + "/latest", commandPath + "/stable":
+			fmt.Fprint(w, `{"version":"1.0.3"}`)
+		default:
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"statusCode": 404,"error": "Not Found","message": "Not Found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNextVersion(t *testing.T) {
+	published := []string{"1.0.0", "1.0.10", "1.0.9", "1.1.0", "1.0.x"}
+	assert.Equal(t, "1.0.11", nextVersion("1.0", published))
+	assert.Equal(t, "1.1.1", nextVersion("1.1", published))
+	assert.Equal(t, "2.0.0", nextVersion("2.0", published))
+	assert.Equal(t, "1.0.0", nextVersion("1.0", nil))
+	assert.Equal(t, "1.0.5", nextVersion("1.0.5", published))
+}
+
+func TestDryRun(t *testing.T) {
+	server := newDryRunServer(t)
+	out := new(bytes.Buffer)
+	sdAPI := DryRun(New(server.URL+"/v4/", fakeSDToken), out)
+	assert.Equal(t, sdAPI, DryRun(sdAPI, nil))
+
+	// post a binary
+	spec := dummySpec(binaryFormat)
+	spec.Version = "1.0"
+	spec.Binary.File = binaryFilePath
+	binary, _ := ioutil.ReadFile(binaryFilePath)
+	res, err := sdAPI.PostCommand(spec)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1.0.4", res.Version)
+		assert.Equal(t, "1.0", spec.Version)
+	}
+	assert.Contains(t, out.String(), fmt.Sprintf("[dry-run] POST %s/v4/commands\n  Content-Type: multipart/form-data\n  part \"spec\": {", server.URL))
+	assert.Contains(t, out.String(), fmt.Sprintf("  part \"file\": file hello (%d bytes)\n", len(binary)))
+
+	// post a docker image
+	out.Reset()
+	spec = dummySpec(dockerFormat)
+	spec.Version = "3.0"
+	res, err = sdAPI.PostCommand(spec)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "3.0.0", res.Version)
+	}
+	assert.Contains(t, out.String(), "  Content-Type: application/json\n  {\"yaml\":")
+
+	// tag
+	out.Reset()
+	tagRes, err := sdAPI.TagCommand(dummySmallSpec(), "latest", "stable")
+	if assert.Nil(t, err) {
+		assert.Equal(t, util.TagResponse{Namespace: dummyNamespace, Name: dummyName, Tag: "stable", Version: "1.0.3"}, *tagRes)
+	}
+	expected := fmt.Sprintf("[dry-run] PUT %s/v4/commands/%s/%s/tags/stable\n  Content-Type: application/json\n  {\"version\":\"latest\"}\n", server.URL, dummyNamespace, dummyName)
+	assert.Equal(t, expected, out.String())
+
+	// the version to publish does not exist yet
+	tagRes, err = sdAPI.TagCommand(dummySmallSpec(), "1.0.4", "stable")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1.0.4", tagRes.Version)
+	}
+
+	// remove tag
+	out.Reset()
+	tagRes, err = sdAPI.RemoveTagCommand(dummySmallSpec(), "stable")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1.0.3", tagRes.Version)
+	}
+	assert.Equal(t, fmt.Sprintf("[dry-run] DELETE %s/v4/commands/%s/%s/tags/stable\n", server.URL, dummyNamespace, dummyName), out.String())
+
+	// the read requests are sent
+	versions, err := sdAPI.GetCommandVersions(dummySmallSpec())
+	assert.Nil(t, err)
+	assert.Len(t, versions, 4)
+}
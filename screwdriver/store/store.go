@@ -49,13 +49,30 @@ func (e ResponseError) Error() string {
 	return fmt.Sprintf("Store API %d %s", e.StatusCode, e.Reason)
 }
 
+// isStoredFormat returns true if the command of the format has a file in Store
+func isStoredFormat(format string) bool {
+	switch format {
+	case "binary", "habitat", "archive", "script":
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *client) commandURL() (string, error) {
-	if c.spec != nil && (c.spec.Format == "binary" || c.spec.Format == "habitat") {
+	if c.spec != nil && isStoredFormat(c.spec.Format) {
 		uri, err := url.Parse(c.baseURL)
 		if err != nil {
 			return "", fmt.Errorf("The base Screwdriver Store API is invalid %q", c.baseURL)
 		}
 		uri.Path = path.Join(uri.Path, "commands", c.spec.Namespace, c.spec.Name, c.spec.Version)
+		// a variant of a binary for multiple platforms
+		if c.spec.Binary != nil && c.spec.Binary.OS != "" {
+			query := uri.Query()
+			query.Set("os", c.spec.Binary.OS)
+			query.Set("arch", c.spec.Binary.Arch)
+			uri.RawQuery = query.Encode()
+		}
 		return uri.String(), nil
 	}
 	return "", fmt.Errorf("The format %s is not expected", c.spec.Format)
@@ -100,6 +100,42 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestCommandURL(t *testing.T) {
+	spec := dummyCommandSpec(binaryFormat)
+	c := newClient(config.SDStoreURL, spec, config.SDToken)
+	uri, err := c.commandURL()
+	expected := "http://fake.store/v1/commands/foo-dummy/name-dummy/1.1.1"
+	if err != nil || uri != expected {
+		t.Errorf("uri=%q, err=%v, want %q", uri, err, expected)
+	}
+
+	// a variant of a binary for multiple platforms
+	spec.Binary = &util.Binary{OS: "linux", Arch: "arm64", File: dummyFile}
+	uri, err = c.commandURL()
+	expected = "http://fake.store/v1/commands/foo-dummy/name-dummy/1.1.1?arch=arm64&os=linux"
+	if err != nil || uri != expected {
+		t.Errorf("uri=%q, err=%v, want %q", uri, err, expected)
+	}
+
+	// an archive and a script
+	for _, format := range []string{"archive", "script"} {
+		spec = &util.CommandSpec{Namespace: "foo-dummy", Name: "name-dummy", Version: "1.1.1", Format: format}
+		c = newClient(config.SDStoreURL, spec, config.SDToken)
+		uri, err = c.commandURL()
+		expected = "http://fake.store/v1/commands/foo-dummy/name-dummy/1.1.1"
+		if err != nil || uri != expected {
+			t.Errorf("format=%s, uri=%q, err=%v, want %q", format, uri, err, expected)
+		}
+	}
+
+	// failure. docker has no file in Store
+	spec = &util.CommandSpec{Namespace: "foo-dummy", Name: "name-dummy", Version: "1.1.1", Format: "docker"}
+	c = newClient(config.SDStoreURL, spec, config.SDToken)
+	if _, err = c.commandURL(); err == nil {
+		t.Errorf("err=nil, want error")
+	}
+}
+
 func TestGetCommand(t *testing.T) {
 	// success
 	spec := dummyCommandSpec(binaryFormat)
@@ -3,17 +3,21 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"runtime/debug"
-	"syscall"
 
+	"github.com/screwdriver-cd/sd-cmd/cache"
 	"github.com/screwdriver-cd/sd-cmd/config"
 	"github.com/screwdriver-cd/sd-cmd/executor"
+	"github.com/screwdriver-cd/sd-cmd/info"
+	"github.com/screwdriver-cd/sd-cmd/lister"
+	"github.com/screwdriver-cd/sd-cmd/lockfile"
 	"github.com/screwdriver-cd/sd-cmd/promoter"
 	"github.com/screwdriver-cd/sd-cmd/publisher"
 	"github.com/screwdriver-cd/sd-cmd/removeTag"
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/tags"
 	"github.com/screwdriver-cd/sd-cmd/validator"
+	"github.com/screwdriver-cd/sd-cmd/versions"
 )
 
 const (
@@ -29,10 +33,8 @@ func successExit() {
 func failureExit(err error) {
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				os.Exit(status.ExitStatus())
-			}
+		if code, ok := executor.ExitCode(err); ok {
+			os.Exit(code)
 		}
 	}
 	os.Exit(defaultFailureExitCode)
@@ -56,10 +58,12 @@ func init() {
 func runExecutor(sdAPI api.API, args []string) (err error) {
 	exec, err := executor.New(sdAPI, args)
 	if err != nil {
+		executor.WriteReport(err)
 		return
 	}
 	defer executor.CleanUp()
 	err = exec.Run()
+	executor.WriteReport(err)
 	return
 }
 
@@ -96,6 +100,70 @@ func runRemoveTag(sdAPI api.API, args []string) error {
 	return val.Run()
 }
 
+func runCache(args []string) error {
+	cm, err := cache.New(args)
+	if err != nil {
+		return fmt.Errorf("Fail to get cache manager: %v", err)
+	}
+	return cm.Run()
+}
+
+func runLocker(sdAPI api.API, args []string) error {
+	l, err := lockfile.New(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get locker: %v", err)
+	}
+	return l.Run()
+}
+
+func runInfo(sdAPI api.API, args []string) error {
+	i, err := info.New(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get info: %v", err)
+	}
+	return i.Run()
+}
+
+func runLister(sdAPI api.API, args []string) error {
+	l, err := lister.New(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get lister: %v", err)
+	}
+	return l.Run()
+}
+
+func runSearcher(sdAPI api.API, args []string) error {
+	l, err := lister.NewSearch(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get searcher: %v", err)
+	}
+	return l.Run()
+}
+
+func runVersions(sdAPI api.API, args []string) error {
+	v, err := versions.New(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get versions: %v", err)
+	}
+	return v.Run()
+}
+
+func runTags(sdAPI api.API, args []string) error {
+	m, err := tags.New(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get tags: %v", err)
+	}
+	return m.Run()
+}
+
+func runRollback(sdAPI api.API, args []string) error {
+	m, err := tags.NewRollback(sdAPI, args)
+	if err != nil {
+		return fmt.Errorf("Fail to get rollback: %v", err)
+	}
+	return m.Run()
+}
+
 func runCommand(sdAPI api.API, args []string) error {
 	if len(args) < minArgLength {
 		return fmt.Errorf("The number of arguments is not enough")
@@ -112,6 +180,22 @@ func runCommand(sdAPI api.API, args []string) error {
 		return runValidator(sdAPI, args[2:])
 	case "removeTag":
 		return runRemoveTag(sdAPI, args[2:])
+	case "cache":
+		return runCache(args[2:])
+	case "lock":
+		return runLocker(sdAPI, args[2:])
+	case "info":
+		return runInfo(sdAPI, args[2:])
+	case "list":
+		return runLister(sdAPI, args[2:])
+	case "search":
+		return runSearcher(sdAPI, args[2:])
+	case "versions":
+		return runVersions(sdAPI, args[2:])
+	case "tags":
+		return runTags(sdAPI, args[2:])
+	case "rollback":
+		return runRollback(sdAPI, args[2:])
 	default:
 		return runExecutor(sdAPI, args[1:])
 	}
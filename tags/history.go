@@ -0,0 +1,129 @@
+// Package tags records the moves of command tags and rolls them back.
+package tags
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+const (
+	// ActionPromote is a move by sd-cmd promote
+	ActionPromote = "promote"
+	// ActionRemove is a move by sd-cmd removeTag
+	ActionRemove = "remove"
+	// ActionRollback is a move by sd-cmd rollback
+	ActionRollback = "rollback"
+)
+
+// Move is a record of moving a tag of a command from a version to another
+type Move struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Tag       string `json:"tag"`
+	// From is the version which the tag pointed at before the move. It is empty if the tag did not exist.
+	From string `json:"from"`
+	// To is the version which the tag points at after the move. It is empty if the tag is removed.
+	To     string    `json:"to"`
+	Action string    `json:"action"`
+	Time   time.Time `json:"time"`
+}
+
+// historyPath returns the path of the history file, or "" if neither SD_CMD_TAG_HISTORY nor SD_ARTIFACTS_DIR is set
+func historyPath() string {
+	if config.TagHistoryPath != "" {
+		return config.TagHistoryPath
+	}
+	if config.SDArtifactsDir == "" {
+		return ""
+	}
+	return filepath.Join(config.SDArtifactsDir, ".sd", "tags", "history.jsonl")
+}
+
+// Record appends the move to the history file as a line of JSON.
+// It does nothing if the history file is not available.
+func Record(move *Move) error {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+	if move.Time.IsZero() {
+		move.Time = time.Now()
+	}
+
+	data, err := json.Marshal(move)
+	if err != nil {
+		return fmt.Errorf("Failed to convert the tag move to json: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("Failed to create tag history directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("Failed to open tag history file: %v", err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	closeError := file.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to write tag history file: %v", err)
+	}
+	if closeError != nil {
+		return fmt.Errorf("Failed to close tag history file: %v", closeError)
+	}
+	return nil
+}
+
+// History returns the recorded moves of the tag of the command from the oldest
+func History(namespace, name, tag string) ([]*Move, error) {
+	path := historyPath()
+	if path == "" {
+		return nil, fmt.Errorf("The tag history is not available, set SD_CMD_TAG_HISTORY or SD_ARTIFACTS_DIR")
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open tag history file: %v", err)
+	}
+	defer file.Close()
+
+	var moves []*Move
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		move := new(Move)
+		if err := json.Unmarshal(scanner.Bytes(), move); err != nil {
+			return nil, fmt.Errorf("Failed to parse tag history file: %v", err)
+		}
+		if move.Namespace == namespace && move.Name == name && move.Tag == tag {
+			moves = append(moves, move)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read tag history file: %v", err)
+	}
+	return moves, nil
+}
+
+// lastMove returns the move which a rollback undoes, or nil if there is none.
+// A rollback undoes the last move which is not rolled back yet, so repeated rollbacks go back further.
+func lastMove(moves []*Move) *Move {
+	var stack []*Move
+	for _, move := range moves {
+		if move.Action != ActionRollback {
+			stack = append(stack, move)
+		} else if len(stack) > 0 {
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[len(stack)-1]
+}
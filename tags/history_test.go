@@ -0,0 +1,107 @@
+package tags
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/config"
+)
+
+const (
+	dummyNameSpace = "foo-dummy"
+	dummyName      = "name-dummy"
+	dummyTag       = "stable"
+)
+
+func setup(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_tags")
+	artifactsDir := config.SDArtifactsDir
+	config.SDArtifactsDir = dir
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+		config.SDArtifactsDir = artifactsDir
+		config.TagHistoryPath = ""
+	})
+}
+
+func dummyMove(from, to, action string) *Move {
+	return &Move{Namespace: dummyNameSpace, Name: dummyName, Tag: dummyTag, From: from, To: to, Action: action}
+}
+
+func TestHistoryPath(t *testing.T) {
+	setup(t)
+	assert.Equal(t, filepath.Join(config.SDArtifactsDir, ".sd/tags/history.jsonl"), historyPath())
+
+	config.TagHistoryPath = "/tmp/history.jsonl"
+	assert.Equal(t, "/tmp/history.jsonl", historyPath())
+
+	config.TagHistoryPath = ""
+	config.SDArtifactsDir = ""
+	assert.Equal(t, "", historyPath())
+}
+
+func TestRecord(t *testing.T) {
+	setup(t)
+
+	// nothing is recorded yet
+	moves, err := History(dummyNameSpace, dummyName, dummyTag)
+	assert.Nil(t, err)
+	assert.Empty(t, moves)
+
+	assert.Nil(t, Record(dummyMove("", "1.0.0", ActionPromote)))
+	assert.Nil(t, Record(&Move{Namespace: dummyNameSpace, Name: dummyName, Tag: "latest", To: "1.0.0", Action: ActionPromote}))
+	recordedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	move := dummyMove("1.0.0", "1.1.0", ActionPromote)
+	move.Time = recordedAt
+	assert.Nil(t, Record(move))
+
+	// the moves of the other tag are excluded
+	moves, err = History(dummyNameSpace, dummyName, dummyTag)
+	assert.Nil(t, err)
+	if assert.Len(t, moves, 2) {
+		assert.Equal(t, "1.0.0", moves[0].To)
+		assert.False(t, moves[0].Time.IsZero())
+		assert.Equal(t, "1.1.0", moves[1].To)
+		assert.Equal(t, recordedAt, moves[1].Time.UTC())
+	}
+
+	// failure. broken history file
+	ioutil.WriteFile(historyPath(), []byte("{broken\n"), 0666)
+	_, err = History(dummyNameSpace, dummyName, dummyTag)
+	assert.NotNil(t, err)
+
+	// the history is not available without the path
+	config.SDArtifactsDir = ""
+	assert.Nil(t, Record(dummyMove("1.1.0", "1.2.0", ActionPromote)))
+	_, err = History(dummyNameSpace, dummyName, dummyTag)
+	assert.NotNil(t, err)
+}
+
+func TestLastMove(t *testing.T) {
+	assert.Nil(t, lastMove(nil))
+
+	moves := []*Move{
+		dummyMove("", "1.0.0", ActionPromote),
+		dummyMove("1.0.0", "1.1.0", ActionPromote),
+		dummyMove("1.1.0", "1.2.0", ActionPromote),
+	}
+	assert.Equal(t, moves[2], lastMove(moves))
+
+	// repeated rollbacks go back further
+	moves = append(moves, dummyMove("1.2.0", "1.1.0", ActionRollback))
+	assert.Equal(t, moves[1], lastMove(moves))
+	moves = append(moves, dummyMove("1.1.0", "1.0.0", ActionRollback))
+	assert.Equal(t, moves[0], lastMove(moves))
+
+	// a new move after rollbacks is rolled back first
+	moves = append(moves, dummyMove("1.0.0", "", ActionRemove))
+	assert.Equal(t, moves[5], lastMove(moves))
+
+	moves = append(moves, dummyMove("", "1.0.0", ActionRollback), dummyMove("1.0.0", "", ActionRollback))
+	assert.Nil(t, lastMove(moves))
+}
@@ -0,0 +1,149 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// Manager is a type to show the history of a tag and roll it back
+type Manager struct {
+	action    string
+	smallSpec *util.CommandSpec
+	sdAPI     api.API
+	tag       string
+	out       io.Writer
+}
+
+func newManager(api api.API, action, command, tag string) (*Manager, error) {
+	commandName := strings.Split(command, "/") // namespace/name
+	if len(commandName) != 2 {
+		return nil, fmt.Errorf("%v is invalid command name", command)
+	}
+
+	if !util.ValidateTagName(tag) {
+		return nil, fmt.Errorf("%v is invalid tag name", tag)
+	}
+
+	return &Manager{
+		action: action,
+		smallSpec: &util.CommandSpec{
+			Namespace: commandName[0],
+			Name:      commandName[1],
+			Version:   tag,
+		},
+		sdAPI: api,
+		tag:   tag,
+		out:   os.Stdout,
+	}, nil
+}
+
+// New generates new Manager.
+// args is expected as ["history", "namespace/name", "tag"]
+func New(api api.API, args []string) (*Manager, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("parameters are not enough")
+	}
+	if args[0] != "history" {
+		return nil, fmt.Errorf("%v is invalid tags action", args[0])
+	}
+	if len(args) != 3 {
+		return nil, fmt.Errorf("parameters are not enough")
+	}
+	return newManager(api, args[0], args[1], args[2])
+}
+
+// NewRollback generates new Manager to roll back a tag.
+// args is expected as ["namespace/name", "tag"]
+func NewRollback(api api.API, args []string) (*Manager, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("parameters are not enough")
+	}
+	return newManager(api, "rollback", args[0], args[1])
+}
+
+func (m *Manager) history() error {
+	moves, err := History(m.smallSpec.Namespace, m.smallSpec.Name, m.tag)
+	if err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		fmt.Fprintf(m.out, "No move of %v is recorded\n", m.tag)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(m.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTION\tFROM\tTO")
+	for _, move := range moves {
+		from, to := move.From, move.To
+		if from == "" {
+			from = "-"
+		}
+		if to == "" {
+			to = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", move.Time.UTC().Format(time.RFC3339), move.Action, from, to)
+	}
+	return w.Flush()
+}
+
+// rollback moves the tag back to the version which it pointed at before the last recorded move
+func (m *Manager) rollback() error {
+	moves, err := History(m.smallSpec.Namespace, m.smallSpec.Name, m.tag)
+	if err != nil {
+		return err
+	}
+	move := lastMove(moves)
+	if move == nil {
+		return fmt.Errorf("No move of %v to roll back is recorded", m.tag)
+	}
+	if move.From == "" {
+		return fmt.Errorf("%v did not exist before it was moved to %v, remove it by removeTag instead", m.tag, move.To)
+	}
+
+	// refuse if the tag was moved without sd-cmd after the last recorded move
+	current := ""
+	if spec, err := m.sdAPI.GetCommand(m.smallSpec); err == nil {
+		current = spec.Version
+	}
+	if current != move.To {
+		if current == "" {
+			current = "no version"
+		}
+		return fmt.Errorf("%v points at %v instead of %v recorded last, it has been moved outside of sd-cmd", m.tag, current, move.To)
+	}
+
+	res, err := m.sdAPI.TagCommand(m.smallSpec, move.From, m.tag)
+	if err != nil {
+		fmt.Fprintln(m.out, "Rollback is aborted")
+		return err
+	}
+	fmt.Fprintf(m.out, "Rolling back %v to %v\n", res.Tag, res.Version)
+
+	err = Record(&Move{
+		Namespace: m.smallSpec.Namespace,
+		Name:      m.smallSpec.Name,
+		Tag:       m.tag,
+		From:      current,
+		To:        res.Version,
+		Action:    ActionRollback,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+	}
+	return nil
+}
+
+// Run executes the tags action
+func (m *Manager) Run() error {
+	if m.action == "rollback" {
+		return m.rollback()
+	}
+	return m.history()
+}
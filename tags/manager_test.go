@@ -0,0 +1,176 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// dummySDAPI has the versions which tags point at
+type dummySDAPI struct {
+	tags   map[string]string
+	tagErr error
+}
+
+func (d *dummySDAPI) GetCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	version, ok := d.tags[smallSpec.Version]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return &util.CommandSpec{Namespace: smallSpec.Namespace, Name: smallSpec.Name, Version: version}, nil
+}
+
+func (d *dummySDAPI) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) ValidateCommand(yamlString string) (*util.ValidateResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) TagCommand(spec *util.CommandSpec, targetVersion, tag string) (*util.TagResponse, error) {
+	if d.tagErr != nil {
+		return nil, d.tagErr
+	}
+	d.tags[tag] = targetVersion
+	return &util.TagResponse{Namespace: spec.Namespace, Name: spec.Name, Tag: tag, Version: targetVersion}, nil
+}
+
+func (d *dummySDAPI) RemoveTagCommand(spec *util.CommandSpec, tag string) (*util.TagResponse, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPI) SetVerbose(isVerbose bool) {}
+
+func TestNew(t *testing.T) {
+	m, err := New(nil, []string{"history", dummyNameSpace + "/" + dummyName, dummyTag})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "history", m.action)
+		assert.Equal(t, dummyNameSpace, m.smallSpec.Namespace)
+		assert.Equal(t, dummyTag, m.smallSpec.Version)
+	}
+
+	m, err = NewRollback(nil, []string{dummyNameSpace + "/" + dummyName, dummyTag})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "rollback", m.action)
+	}
+
+	// failure
+	_, err = New(nil, []string{"history", dummyNameSpace + "/" + dummyName})
+	assert.EqualError(t, err, "parameters are not enough")
+	_, err = New(nil, []string{"ls", dummyNameSpace + "/" + dummyName, dummyTag})
+	assert.EqualError(t, err, "ls is invalid tags action")
+	_, err = NewRollback(nil, []string{"invalid/invalid/invalid", dummyTag})
+	assert.EqualError(t, err, "invalid/invalid/invalid is invalid command name")
+	_, err = NewRollback(nil, []string{dummyNameSpace + "/" + dummyName, "-invalid-"})
+	assert.EqualError(t, err, "-invalid- is invalid tag name")
+}
+
+func newTestManager(t *testing.T, sdAPI *dummySDAPI, args ...string) (*Manager, *bytes.Buffer) {
+	var (
+		m   *Manager
+		err error
+	)
+	if args[0] == "history" {
+		m, err = New(sdAPI, args)
+	} else {
+		m, err = NewRollback(sdAPI, args)
+	}
+	if err != nil {
+		t.Fatalf("err=%v, want nil", err)
+	}
+	out := new(bytes.Buffer)
+	m.out = out
+	return m, out
+}
+
+func TestRunHistory(t *testing.T) {
+	setup(t)
+	command := dummyNameSpace + "/" + dummyName
+
+	m, out := newTestManager(t, nil, "history", command, dummyTag)
+	assert.Nil(t, m.Run())
+	assert.Equal(t, "No move of stable is recorded\n", out.String())
+
+	for _, move := range []*Move{dummyMove("", "1.0.0", ActionPromote), dummyMove("1.0.0", "", ActionRemove)} {
+		move.Time, _ = time.Parse(time.RFC3339, "2020-01-02T03:04:05Z")
+		Record(move)
+	}
+	m, out = newTestManager(t, nil, "history", command, dummyTag)
+	assert.Nil(t, m.Run())
+	expected := `TIME                  ACTION   FROM   TO
+2020-01-02T03:04:05Z  promote  -      1.0.0
+2020-01-02T03:04:05Z  remove   1.0.0  -
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRunRollback(t *testing.T) {
+	setup(t)
+	command := dummyNameSpace + "/" + dummyName
+	sdAPI := &dummySDAPI{tags: map[string]string{dummyTag: "1.2.0"}}
+
+	// failure. nothing is recorded
+	m, _ := newTestManager(t, sdAPI, command, dummyTag)
+	assert.EqualError(t, m.Run(), "No move of stable to roll back is recorded")
+
+	Record(dummyMove("", "1.0.0", ActionPromote))
+	Record(dummyMove("1.0.0", "1.1.0", ActionPromote))
+	Record(dummyMove("1.1.0", "1.2.0", ActionPromote))
+
+	// success. back to the previous version, and then further back
+	m, out := newTestManager(t, sdAPI, command, dummyTag)
+	assert.Nil(t, m.Run())
+	assert.Equal(t, "Rolling back stable to 1.1.0\n", out.String())
+	assert.Equal(t, "1.1.0", sdAPI.tags[dummyTag])
+
+	m, _ = newTestManager(t, sdAPI, command, dummyTag)
+	assert.Nil(t, m.Run())
+	assert.Equal(t, "1.0.0", sdAPI.tags[dummyTag])
+
+	moves, _ := History(dummyNameSpace, dummyName, dummyTag)
+	if assert.Len(t, moves, 5) {
+		assert.Equal(t, ActionRollback, moves[4].Action)
+		assert.Equal(t, "1.1.0", moves[4].From)
+		assert.Equal(t, "1.0.0", moves[4].To)
+	}
+
+	// failure. the tag did not exist before the first promotion
+	m, _ = newTestManager(t, sdAPI, command, dummyTag)
+	assert.EqualError(t, m.Run(), "stable did not exist before it was moved to 1.0.0, remove it by removeTag instead")
+
+	// failure. the tag is moved outside of sd-cmd
+	Record(dummyMove("1.0.0", "1.3.0", ActionPromote))
+	m, _ = newTestManager(t, sdAPI, command, dummyTag)
+	assert.EqualError(t, m.Run(), "stable points at 1.0.0 instead of 1.3.0 recorded last, it has been moved outside of sd-cmd")
+	delete(sdAPI.tags, dummyTag)
+	assert.EqualError(t, m.Run(), "stable points at no version instead of 1.3.0 recorded last, it has been moved outside of sd-cmd")
+
+	// failure. error response from TagCommand
+	sdAPI.tags[dummyTag] = "1.3.0"
+	sdAPI.tagErr = fmt.Errorf("error")
+	m, out = newTestManager(t, sdAPI, command, dummyTag)
+	assert.EqualError(t, m.Run(), "error")
+	assert.Equal(t, "Rollback is aborted\n", out.String())
+}
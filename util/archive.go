@@ -0,0 +1,241 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CreateTarGz writes a gzipped tarball of the files under dir to w.
+// Paths in the tarball are relative to dir.
+func CreateTarGz(dir string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to archive %s: %v", dir, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("Failed to close tarball: %v", err)
+	}
+	return gw.Close()
+}
+
+// SecureJoin returns the path of name under dest.
+// It returns an error if name points outside of dest such as "../foo" or "/etc/passwd".
+func SecureJoin(dest, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("%q is an absolute path", name)
+	}
+	target := filepath.Join(dest, name)
+	if target != filepath.Clean(dest) && !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%q is outside of the destination", name)
+	}
+	return target, nil
+}
+
+// secureLink checks that the symbolic link at target does not point outside of dest
+func secureLink(dest, target, link string) error {
+	if filepath.IsAbs(link) {
+		return fmt.Errorf("the link %q is an absolute path", link)
+	}
+	rel, err := filepath.Rel(dest, filepath.Join(filepath.Dir(target), link))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return fmt.Errorf("the link %q is outside of the destination", link)
+	}
+	return nil
+}
+
+// checkParents checks that no parent directory of target under dest is a symbolic link,
+// otherwise an entry could be written outside of dest through the link.
+func checkParents(dest, target string) error {
+	rel, err := filepath.Rel(dest, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	dir := dest
+	for _, name := range strings.Split(rel, string(os.PathSeparator)) {
+		dir = filepath.Join(dir, name)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("the parent %q is a symbolic link", dir)
+		}
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return err
+	}
+	// O_EXCL does not follow a symbolic link which is put in the place of the file
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, r)
+	closeError := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeError
+}
+
+func writeLink(dest, target, link string) error {
+	if err := secureLink(dest, target, link); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+func extractTarGz(data []byte, dest string) error {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := SecureJoin(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkParents(dest, target); err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0777)
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeFile(target, tr, os.FileMode(header.Mode))
+		case tar.TypeSymlink:
+			err = writeLink(dest, target, header.Linkname)
+		default:
+			err = fmt.Errorf("the type of %q is not supported", header.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to extract %s: %v", header.Name, err)
+		}
+	}
+}
+
+func extractZipFile(f *zip.File, dest string) error {
+	target, err := SecureJoin(dest, f.Name)
+	if err != nil {
+		return err
+	}
+	if err := checkParents(dest, target); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	mode := f.Mode()
+	switch {
+	case mode.IsDir():
+		return os.MkdirAll(target, 0777)
+	case mode&os.ModeSymlink != 0:
+		link, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		return writeLink(dest, target, string(link))
+	case mode.IsRegular():
+		return writeFile(target, rc, mode)
+	default:
+		return fmt.Errorf("the type of %q is not supported", f.Name)
+	}
+}
+
+func extractZip(data []byte, dest string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if err := extractZipFile(f, dest); err != nil {
+			return fmt.Errorf("Failed to extract %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// ExtractArchive extracts a gzipped tarball or a zip archive into dest.
+// The format is detected from the content. Entries which point outside of dest are refused.
+func ExtractArchive(data []byte, dest string) error {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return extractTarGz(data, dest)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return extractZip(data, dest)
+	default:
+		return fmt.Errorf("The archive is neither a gzipped tarball nor a zip archive")
+	}
+}
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type archiveEntry struct {
+	name string
+	body string
+	link string
+	dir  bool
+}
+
+func makeTarGz(t *testing.T, entries []archiveEntry) []byte {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case e.dir:
+			header.Typeflag = tar.TypeDir
+			header.Size = 0
+		case e.link != "":
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.link
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		tw.Write([]byte(e.body))
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, entries []archiveEntry) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name}
+		body := e.body
+		header.SetMode(0755)
+		if e.link != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+			body = e.link
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("failed to create header: %v", err)
+		}
+		w.Write([]byte(body))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestCreateTarGz(t *testing.T) {
+	src, _ := ioutil.TempDir("", "sd-cmd_archive")
+	defer os.RemoveAll(src)
+	os.MkdirAll(filepath.Join(src, "lib"), 0777)
+	ioutil.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	ioutil.WriteFile(filepath.Join(src, "lib", "data.txt"), []byte("data"), 0644)
+	os.Symlink("lib/data.txt", filepath.Join(src, "data.txt"))
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, CreateTarGz(src, buf))
+
+	dest, _ := ioutil.TempDir("", "sd-cmd_archive")
+	defer os.RemoveAll(dest)
+	assert.Nil(t, ExtractArchive(buf.Bytes(), dest))
+
+	info, err := os.Stat(filepath.Join(dest, "run.sh"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dest, "lib", "data.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "data", string(data))
+	link, err := os.Readlink(filepath.Join(dest, "data.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "lib/data.txt", link)
+
+	// failure. the directory does not exist
+	assert.NotNil(t, CreateTarGz(filepath.Join(src, "notexist"), new(bytes.Buffer)))
+}
+
+func TestExtractArchive(t *testing.T) {
+	valid := []archiveEntry{
+		{name: "bin/", dir: true},
+		{name: "bin/run.sh", body: "#!/bin/sh\n"},
+		{name: "run", link: "bin/run.sh"},
+	}
+
+	// success
+	for format, data := range map[string][]byte{
+		"tar.gz": makeTarGz(t, valid),
+		"zip":    makeZip(t, valid),
+	} {
+		dest, _ := ioutil.TempDir("", "sd-cmd_archive")
+		assert.Nil(t, ExtractArchive(data, dest), format)
+		body, err := ioutil.ReadFile(filepath.Join(dest, "run"))
+		assert.Nil(t, err, format)
+		assert.Equal(t, "#!/bin/sh\n", string(body), format)
+		os.RemoveAll(dest)
+	}
+
+	// failure. entries which point outside of the destination
+	for _, entries := range [][]archiveEntry{
+		{{name: "../evil", body: "evil"}},
+		{{name: "bin/../../evil", body: "evil"}},
+		{{name: "/tmp/evil", body: "evil"}},
+		{{name: "link", link: "../evil"}},
+		{{name: "link", link: "/etc/passwd"}},
+		{{name: "link", link: "."}, {name: "link/evil", link: "../evil"}},
+	} {
+		for format, data := range map[string][]byte{
+			"tar.gz": makeTarGz(t, entries),
+			"zip":    makeZip(t, entries),
+		} {
+			parent, _ := ioutil.TempDir("", "sd-cmd_archive")
+			dest := filepath.Join(parent, "dest")
+			os.Mkdir(dest, 0777)
+			assert.NotNil(t, ExtractArchive(data, dest), format, entries)
+			_, err := os.Lstat(filepath.Join(parent, "evil"))
+			assert.True(t, os.IsNotExist(err), format, entries)
+			os.RemoveAll(parent)
+		}
+	}
+
+	// failure. unknown format
+	assert.NotNil(t, ExtractArchive([]byte("plain text"), os.TempDir()))
+}
+
+func TestSecureJoin(t *testing.T) {
+	path, err := SecureJoin("/foo", "bar/baz")
+	assert.Nil(t, err)
+	assert.Equal(t, "/foo/bar/baz", path)
+
+	for _, name := range []string{"../bar", "bar/../../baz", "/bar"} {
+		_, err := SecureJoin("/foo", name)
+		assert.NotNil(t, err, name)
+	}
+}
@@ -0,0 +1,281 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// argTypes are the types of flags and arguments. The empty type is string.
+var argTypes = []string{"string", "int", "number", "bool"}
+
+// An ArgSpec represents a flag or a positional argument of the command.
+// Variadic is only allowed for the last positional argument, which takes all the rest of the arguments.
+type ArgSpec struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Required    bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Variadic    bool     `json:"variadic,omitempty" yaml:"variadic,omitempty"`
+}
+
+// An ArgsSchema represents the flags and the positional arguments which the command accepts.
+// Flags are given as --name value, --name=value or -name, and a bool flag takes no value.
+type ArgsSchema struct {
+	Flags     []*ArgSpec `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Arguments []*ArgSpec `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+}
+
+func (a *ArgSpec) typeName() string {
+	if a.Type == "" {
+		return "string"
+	}
+	return a.Type
+}
+
+// checkValue checks that value is of the type and one of the enum values
+func (a *ArgSpec) checkValue(value string) error {
+	var err error
+	switch a.Type {
+	case "int":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not %s", value, a.typeName())
+	}
+	if len(a.Enum) == 0 {
+		return nil
+	}
+	for _, v := range a.Enum {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(a.Enum, ", "))
+}
+
+// validateSpecs returns the reasons why the flags or the arguments are not valid
+func validateSpecs(kind string, specs []*ArgSpec) []string {
+	var reasons []string
+	names := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.Name == "" {
+			reasons = append(reasons, fmt.Sprintf("A %s has no name", kind))
+			continue
+		}
+		if names[spec.Name] {
+			reasons = append(reasons, fmt.Sprintf("The %s %s is declared more than once", kind, spec.Name))
+		}
+		names[spec.Name] = true
+
+		validType := false
+		for _, t := range argTypes {
+			validType = validType || spec.typeName() == t
+		}
+		if !validType {
+			reasons = append(reasons, fmt.Sprintf("The type %q of the %s %s is not one of %s", spec.Type, kind, spec.Name, strings.Join(argTypes, ", ")))
+			continue
+		}
+		for _, value := range spec.Enum {
+			if err := (&ArgSpec{Type: spec.Type}).checkValue(value); err != nil {
+				reasons = append(reasons, fmt.Sprintf("The enum value of the %s %s is invalid: %v", kind, spec.Name, err))
+			}
+		}
+	}
+	return reasons
+}
+
+// Validate returns the reasons why the schema is not valid
+func (s *ArgsSchema) Validate() []string {
+	if s == nil {
+		return nil
+	}
+
+	reasons := validateSpecs("flag", s.Flags)
+	for _, flag := range s.Flags {
+		if flag.Variadic {
+			reasons = append(reasons, fmt.Sprintf("The flag %s cannot be variadic", flag.Name))
+		}
+	}
+	reasons = append(reasons, validateSpecs("argument", s.Arguments)...)
+	optional := ""
+	for i, arg := range s.Arguments {
+		if arg.Variadic && i != len(s.Arguments)-1 {
+			reasons = append(reasons, fmt.Sprintf("The argument %s is variadic but not the last one", arg.Name))
+		}
+		if arg.Required && optional != "" {
+			reasons = append(reasons, fmt.Sprintf("The required argument %s follows the optional argument %s", arg.Name, optional))
+		}
+		if !arg.Required && optional == "" {
+			optional = arg.Name
+		}
+	}
+	return reasons
+}
+
+func (s *ArgsSchema) flag(name string) *ArgSpec {
+	for _, flag := range s.Flags {
+		if flag.Name == name {
+			return flag
+		}
+	}
+	return nil
+}
+
+// Check checks the arguments given to the command against the schema.
+// A nil schema accepts any arguments.
+func (s *ArgsSchema) Check(args []string) error {
+	if s == nil {
+		return nil
+	}
+
+	given := make(map[string]bool)
+	var positionals []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positionals = append(positionals, arg)
+			continue
+		}
+
+		name, value := strings.TrimLeft(arg, "-"), ""
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		flag := s.flag(name)
+		if flag == nil {
+			// a negative number is an argument unless it is declared as a flag
+			if _, err := strconv.ParseFloat(arg, 64); err == nil {
+				positionals = append(positionals, arg)
+				continue
+			}
+			return fmt.Errorf("The flag %s is not defined", arg)
+		}
+		if !hasValue {
+			switch {
+			case flag.Type == "bool":
+				value = "true"
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				return fmt.Errorf("The flag --%s needs a value", name)
+			}
+		}
+		if err := flag.checkValue(value); err != nil {
+			return fmt.Errorf("The value of the flag --%s is invalid: %v", name, err)
+		}
+		given[name] = true
+	}
+
+	for _, flag := range s.Flags {
+		if flag.Required && !given[flag.Name] {
+			return fmt.Errorf("The flag --%s is required", flag.Name)
+		}
+	}
+
+	for i, arg := range s.Arguments {
+		if i >= len(positionals) {
+			if arg.Required {
+				return fmt.Errorf("The argument <%s> is required", arg.Name)
+			}
+			break
+		}
+		values := positionals[i : i+1]
+		if arg.Variadic {
+			values = positionals[i:]
+		}
+		for _, value := range values {
+			if err := arg.checkValue(value); err != nil {
+				return fmt.Errorf("The value of the argument <%s> is invalid: %v", arg.Name, err)
+			}
+		}
+	}
+	last := len(s.Arguments) - 1
+	if len(positionals) > len(s.Arguments) && (last < 0 || !s.Arguments[last].Variadic) {
+		return fmt.Errorf("The argument %q is not expected", positionals[len(s.Arguments)])
+	}
+	return nil
+}
+
+// valueName returns the placeholder of the value in the usage such as <int> or <json|yaml>
+func (a *ArgSpec) valueName() string {
+	if len(a.Enum) > 0 {
+		return "<" + strings.Join(a.Enum, "|") + ">"
+	}
+	return "<" + a.typeName() + ">"
+}
+
+// Usage returns the usage of the command generated from the schema
+func (s *ArgsSchema) Usage(command string) string {
+	if s == nil {
+		return fmt.Sprintf("Usage: %s [arguments...]\n", command)
+	}
+
+	line := "Usage: " + command
+	for _, flag := range s.Flags {
+		if flag.Required {
+			line += fmt.Sprintf(" --%s %s", flag.Name, flag.valueName())
+		}
+	}
+	if len(s.Flags) > 0 {
+		line += " [flags]"
+	}
+	for _, arg := range s.Arguments {
+		name := "<" + arg.Name + ">"
+		if arg.Variadic {
+			name += "..."
+		}
+		if !arg.Required {
+			name = "[" + name + "]"
+		}
+		line += " " + name
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(line + "\n")
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if len(s.Flags) > 0 {
+		fmt.Fprintln(w, "Flags:")
+		for _, flag := range s.Flags {
+			name := "--" + flag.Name
+			if flag.Type != "bool" {
+				name += " " + flag.valueName()
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", name, describe(flag))
+		}
+	}
+	if len(s.Arguments) > 0 {
+		fmt.Fprintln(w, "Arguments:")
+		for _, arg := range s.Arguments {
+			fmt.Fprintf(w, "  <%s> %s\t%s\n", arg.Name, arg.valueName(), describe(arg))
+		}
+	}
+	w.Flush()
+
+	// tabwriter pads the arguments without description
+	lines := strings.Split(buf.String(), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describe(a *ArgSpec) string {
+	if a.Required {
+		return strings.TrimSpace(a.Description + " (required)")
+	}
+	return a.Description
+}
@@ -0,0 +1,105 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dummyArgsSchema() *ArgsSchema {
+	return &ArgsSchema{
+		Flags: []*ArgSpec{
+			{Name: "output", Description: "Output format", Enum: []string{"json", "yaml"}, Required: true},
+			{Name: "count", Type: "int"},
+			{Name: "dry-run", Type: "bool"},
+		},
+		Arguments: []*ArgSpec{
+			{Name: "target", Description: "Target to deploy", Required: true},
+			{Name: "ratio", Type: "number"},
+			{Name: "files", Variadic: true},
+		},
+	}
+}
+
+func TestArgsSchemaValidate(t *testing.T) {
+	var schema *ArgsSchema
+	assert.Empty(t, schema.Validate())
+	assert.Empty(t, dummyArgsSchema().Validate())
+
+	schema = &ArgsSchema{
+		Flags: []*ArgSpec{
+			{Name: "count", Type: "int", Enum: []string{"1", "two"}},
+			{Name: "count"},
+			{},
+			{Name: "all", Variadic: true},
+		},
+		Arguments: []*ArgSpec{
+			{Name: "files", Variadic: true},
+			{Name: "target", Type: "integer"},
+			{Name: "mode", Required: true},
+		},
+	}
+	assert.Equal(t, []string{
+		`The enum value of the flag count is invalid: "two" is not int`,
+		"The flag count is declared more than once",
+		"A flag has no name",
+		"The flag all cannot be variadic",
+		`The type "integer" of the argument target is not one of string, int, number, bool`,
+		"The argument files is variadic but not the last one",
+		"The required argument mode follows the optional argument files",
+	}, schema.Validate())
+}
+
+func TestArgsSchemaCheck(t *testing.T) {
+	// any arguments are accepted without schema
+	var schema *ArgsSchema
+	assert.Nil(t, schema.Check([]string{"--foo", "bar"}))
+
+	schema = dummyArgsSchema()
+	for _, args := range [][]string{
+		{"--output", "json", "prod"},
+		{"--output=yaml", "-count", "3", "--dry-run", "prod", "0.5", "a", "b"},
+		{"prod", "--output", "json", "--dry-run=false", "-1.5"},
+		{"--output", "json", "--", "--prod"},
+	} {
+		assert.Nil(t, schema.Check(args), args)
+	}
+
+	for _, test := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"prod"}, "The flag --output is required"},
+		{[]string{"--output", "xml", "prod"}, `The value of the flag --output is invalid: "xml" is not one of json, yaml`},
+		{[]string{"--output", "json", "--count", "three", "prod"}, `The value of the flag --count is invalid: "three" is not int`},
+		{[]string{"--output", "json", "--verbose", "prod"}, "The flag --verbose is not defined"},
+		{[]string{"prod", "--output"}, "The flag --output needs a value"},
+		{[]string{"--output", "json"}, "The argument <target> is required"},
+		{[]string{"--output", "json", "prod", "half"}, `The value of the argument <ratio> is invalid: "half" is not number`},
+	} {
+		assert.EqualError(t, schema.Check(test.args), test.err, test.args)
+	}
+
+	// too many arguments without a variadic argument
+	schema.Arguments = schema.Arguments[:2]
+	assert.EqualError(t, schema.Check([]string{"--output", "json", "prod", "1", "extra"}), `The argument "extra" is not expected`)
+	schema.Arguments = nil
+	assert.EqualError(t, schema.Check([]string{"--output", "json", "prod"}), `The argument "prod" is not expected`)
+}
+
+func TestArgsSchemaUsage(t *testing.T) {
+	var schema *ArgsSchema
+	assert.Equal(t, "Usage: sd-cmd exec foo/bar@1 [arguments...]\n", schema.Usage("sd-cmd exec foo/bar@1"))
+
+	expected := `Usage: sd-cmd exec foo/bar@1 --output <json|yaml> [flags] <target> [<ratio>] [<files>...]
+Flags:
+  --output <json|yaml>  Output format (required)
+  --count <int>
+  --dry-run
+Arguments:
+  <target> <string>  Target to deploy (required)
+  <ratio> <number>
+  <files> <string>
+`
+	assert.Equal(t, expected, dummyArgsSchema().Usage("sd-cmd exec foo/bar@1"))
+}
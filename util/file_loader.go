@@ -3,6 +3,9 @@ package util
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
 
 	"gopkg.in/yaml.v2"
 )
@@ -44,3 +47,43 @@ func LoadString(filePath string) (string, error) {
 
 	return string(data), nil
 }
+
+// GetBinPath returns the path of a file declared in the command spec.
+// A relative path is treated as relative from the spec yaml.
+func GetBinPath(specPath string, filePath string) string {
+	if path.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(filepath.Dir(specPath), filePath)
+}
+
+// WriteFileAtomically writes data to a temporary file in the directory of filePath and renames it to filePath,
+// so that nobody reads a half-written file. The directory is created if it does not exist.
+func WriteFileAtomically(filePath string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return fmt.Errorf("Failed to create directory %s: %v", dir, err)
+	}
+	tempFile, err := ioutil.TempFile(dir, filepath.Base(filePath))
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary file: %v", err)
+	}
+	tempFileName := tempFile.Name()
+	// ignore error on file remove intentionally
+	defer os.Remove(tempFileName)
+	_, err = tempFile.Write(data)
+	closeError := tempFile.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to write %s: %v", filePath, err)
+	}
+	if closeError != nil {
+		return fmt.Errorf("Failed to close %s: %v", filePath, closeError)
+	}
+	if err := os.Chmod(tempFileName, perm); err != nil {
+		return fmt.Errorf("Failed to change the access permissions of %s: %v", filePath, err)
+	}
+	if err := os.Rename(tempFileName, filePath); err != nil {
+		return fmt.Errorf("Failed to rename temporary file to %s: %v", filePath, err)
+	}
+	return nil
+}
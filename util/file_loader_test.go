@@ -1,7 +1,12 @@
 package util
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var commandSpecYamlPath = "../testdata/yaml/sd-command.yaml"
@@ -58,3 +63,43 @@ func TestLoadString(t *testing.T) {
 		t.Errorf("err=%v, want nil", err)
 	}
 }
+
+func TestGetBinPath(t *testing.T) {
+	testCases := []struct {
+		specPath     string
+		filePath     string
+		expectedPath string
+	}{
+		{"sd-command.yaml", "hello", "hello"},
+		{"sd-command.yaml", "./hello", "hello"},
+		// Note: allow an absolute path.
+		{"sd-command.yaml", "/usr/local/bin/hello", "/usr/local/bin/hello"},
+		{"./sd-command.yaml", "hello", "hello"},
+		{"../../testdata/yaml/sd-command.yaml", "bin/hello", "../../testdata/yaml/bin/hello"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedPath, GetBinPath(tc.specPath, tc.filePath))
+	}
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_util")
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "sub", "file")
+
+	// success. the directory is created and the file is overwritten
+	assert.Nil(t, WriteFileAtomically(filePath, []byte("first"), 0644))
+	assert.Nil(t, WriteFileAtomically(filePath, []byte("second"), 0600))
+	data, _ := ioutil.ReadFile(filePath)
+	assert.Equal(t, "second", string(data))
+	info, _ := os.Stat(filePath)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// no temporary file is left
+	files, _ := ioutil.ReadDir(filepath.Dir(filePath))
+	assert.Len(t, files, 1)
+
+	// failure. the directory can not be created
+	assert.NotNil(t, WriteFileAtomically(filepath.Join(filePath, "file"), []byte("data"), 0644))
+}
@@ -0,0 +1,465 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionRegexp check a full semantic version.
+// ex(1.2.3 1.2.3-beta.1 1.2.3+build.5 1.2.3-rc.1+build.5)
+var versionRegexp = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// partialRegexp check a version which can have wildcards or omit minor and patch.
+// ex(1 1.2 1.x 1.2.* * 1.2.3-beta.1)
+var partialRegexp = regexp.MustCompile(`^(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?` +
+	`(?:-([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// comparatorRegexp splits an operator and a version of a comparator.
+// ex(>=1.2.3 <2 ^1.2 ~1.2.3 =1.2.3)
+var comparatorRegexp = regexp.MustCompile(`^(<=|>=|<|>|=|\^|~>?)?\s*(.+)$`)
+
+// A Version represents a semantic version.
+// See https://semver.org/
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// ParseVersion parses a full semantic version such as 1.2.3-beta.1+build.5
+func ParseVersion(ver string) (*Version, error) {
+	values := versionRegexp.FindStringSubmatch(strings.TrimSpace(ver))
+	if values == nil {
+		return nil, fmt.Errorf("%q is not a semantic version", ver)
+	}
+
+	v := new(Version)
+	for i, component := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		n, err := strconv.ParseUint(values[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a semantic version: %v", ver, err)
+		}
+		*component = n
+	}
+	if values[4] != "" {
+		v.Prerelease = strings.Split(values[4], ".")
+	}
+	if values[5] != "" {
+		v.Build = strings.Split(values[5], ".")
+	}
+	return v, nil
+}
+
+// String returns the version formatted as MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease compares prerelease identifiers.
+// A version without prerelease has higher precedence than one with prerelease.
+func comparePrerelease(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return -compareUint(uint64(len(a)), uint64(len(b)))
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		numA, errA := strconv.ParseUint(a[i], 10, 64)
+		numB, errB := strconv.ParseUint(b[i], 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if c := compareUint(numA, numB); c != 0 {
+				return c
+			}
+		case errA == nil:
+			// numeric identifiers have lower precedence than alphanumeric ones
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(a)), uint64(len(b)))
+}
+
+// Compare returns -1, 0 or 1 when v has lower, same or higher precedence than o.
+// Build metadata is ignored.
+func (v *Version) Compare(o *Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// Bump returns the next version of v at level, one of major, minor or patch.
+// A prerelease version is bumped to its release if the release is the next version at level
+// (e.g. 2.0.0-beta.1 to 2.0.0 by major, 1.2.3-beta.1 to 1.2.3 by patch).
+// Build metadata is dropped.
+func (v *Version) Bump(level string) (*Version, error) {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	isPrerelease := len(v.Prerelease) > 0
+	switch level {
+	case "major":
+		if !isPrerelease || v.Minor != 0 || v.Patch != 0 {
+			next = &Version{Major: v.Major + 1}
+		}
+	case "minor":
+		if !isPrerelease || v.Patch != 0 {
+			next = &Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+	case "patch":
+		if !isPrerelease {
+			next.Patch++
+		}
+	default:
+		return nil, fmt.Errorf("%q is not one of major, minor, patch", level)
+	}
+	return next, nil
+}
+
+// A comparator represents a condition such as >=1.2.3
+type comparator struct {
+	operator string
+	version  *Version
+}
+
+func (c comparator) match(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// A Range represents a version range such as ^1.2.3, ~1.2, 1.x, 1.2.3 - 2.3.4 or >=1.2.0,<2.0.0.
+// Comparator sets can be joined by "||".
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// partial is a version whose components can be omitted. -1 means a wildcard.
+type partial struct {
+	major, minor, patch int64
+	prerelease          []string
+}
+
+func parsePartial(ver string) (*partial, error) {
+	values := partialRegexp.FindStringSubmatch(ver)
+	if values == nil {
+		return nil, fmt.Errorf("%q is not a version", ver)
+	}
+
+	p := &partial{major: -1, minor: -1, patch: -1}
+	components := []*int64{&p.major, &p.minor, &p.patch}
+	wildcard := false
+	for i, value := range values[1:4] {
+		switch value {
+		case "", "x", "X", "*":
+			wildcard = true
+		default:
+			if wildcard {
+				return nil, fmt.Errorf("%q has a version after a wildcard", ver)
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a version: %v", ver, err)
+			}
+			*components[i] = n
+		}
+	}
+	if values[4] != "" {
+		if p.patch < 0 {
+			return nil, fmt.Errorf("%q has a prerelease without a patch version", ver)
+		}
+		p.prerelease = strings.Split(values[4], ".")
+	}
+	return p, nil
+}
+
+// lower returns the lowest version of the partial
+func (p *partial) lower() *Version {
+	v := &Version{Prerelease: p.prerelease}
+	if p.major >= 0 {
+		v.Major = uint64(p.major)
+	}
+	if p.minor >= 0 {
+		v.Minor = uint64(p.minor)
+	}
+	if p.patch >= 0 {
+		v.Patch = uint64(p.patch)
+	}
+	return v
+}
+
+// next returns the lowest version which is higher than any version of the partial.
+// It returns nil if the partial is "*".
+func (p *partial) next() *Version {
+	switch {
+	case p.major < 0:
+		return nil
+	case p.minor < 0:
+		return &Version{Major: uint64(p.major) + 1}
+	default:
+		return &Version{Major: uint64(p.major), Minor: uint64(p.minor) + 1}
+	}
+}
+
+func (p *partial) isFull() bool {
+	return p.patch >= 0
+}
+
+// parseComparator desugars a comparator into primitive comparators
+func parseComparator(s string) ([]comparator, error) {
+	values := comparatorRegexp.FindStringSubmatch(s)
+	if values == nil {
+		return nil, fmt.Errorf("%q is not a comparator", s)
+	}
+	operator := values[1]
+	p, err := parsePartial(values[2])
+	if err != nil {
+		return nil, err
+	}
+
+	lower := p.lower()
+	switch operator {
+	case "", "=":
+		if p.isFull() {
+			return []comparator{{"=", lower}}, nil
+		}
+		if next := p.next(); next != nil {
+			return []comparator{{">=", lower}, {"<", next}}, nil
+		}
+		return []comparator{{">=", lower}}, nil
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+	case "<":
+		return []comparator{{"<", lower}}, nil
+	case ">":
+		if p.isFull() {
+			return []comparator{{">", lower}}, nil
+		}
+		if next := p.next(); next != nil {
+			return []comparator{{">=", next}}, nil
+		}
+		// nothing is higher than "*"
+		return []comparator{{"<", &Version{}}}, nil
+	case "<=":
+		if p.isFull() {
+			return []comparator{{"<=", lower}}, nil
+		}
+		if next := p.next(); next != nil {
+			return []comparator{{"<", next}}, nil
+		}
+		return []comparator{{">=", lower}}, nil
+	case "~", "~>":
+		// ~1.2.3 := >=1.2.3 <1.3.0, ~1.2 := >=1.2.0 <1.3.0, ~1 := >=1.0.0 <2.0.0
+		if p.minor >= 0 {
+			return []comparator{{">=", lower}, {"<", &Version{Major: uint64(p.major), Minor: uint64(p.minor) + 1}}}, nil
+		}
+		if next := p.next(); next != nil {
+			return []comparator{{">=", lower}, {"<", next}}, nil
+		}
+		return []comparator{{">=", lower}}, nil
+	default: // "^"
+		// ^1.2.3 := >=1.2.3 <2.0.0, ^0.2.3 := >=0.2.3 <0.3.0, ^0.0.3 := >=0.0.3 <0.0.4
+		var upper *Version
+		switch {
+		case p.major < 0:
+			return []comparator{{">=", lower}}, nil
+		case p.major > 0 || p.minor < 0:
+			upper = &Version{Major: uint64(p.major) + 1}
+		case p.minor > 0 || p.patch < 0:
+			upper = &Version{Minor: uint64(p.minor) + 1}
+		default:
+			upper = &Version{Patch: uint64(p.patch) + 1}
+		}
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	}
+}
+
+// parseHyphen desugars a hyphen range such as 1.2.3 - 2.3.4
+func parseHyphen(from, to string) ([]comparator, error) {
+	pFrom, err := parsePartial(from)
+	if err != nil {
+		return nil, err
+	}
+	pTo, err := parsePartial(to)
+	if err != nil {
+		return nil, err
+	}
+
+	set := []comparator{{">=", pFrom.lower()}}
+	switch {
+	case pTo.isFull():
+		set = append(set, comparator{"<=", pTo.lower()})
+	case pTo.next() != nil:
+		set = append(set, comparator{"<", pTo.next()})
+	}
+	return set, nil
+}
+
+func parseComparatorSet(s string) ([]comparator, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return []comparator{{">=", &Version{}}}, nil
+	}
+
+	if parts := strings.Split(s, " - "); len(parts) == 2 {
+		return parseHyphen(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	// allow spaces between an operator and a version such as ">= 1.2.3"
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		token := fields[i]
+		if strings.Trim(token, "<>=~^") == "" && i+1 < len(fields) {
+			i++
+			token += fields[i]
+		}
+		tokens = append(tokens, token)
+	}
+
+	var set []comparator
+	for _, token := range tokens {
+		comparators, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// ParseRange parses a version range.
+// It supports exact versions, X-Ranges (1.x 1.2.* *), Tilde Ranges (~1.2.3), Caret Ranges (^1.2.3),
+// Hyphen Ranges (1.2.3 - 2.3.4), comparator sets (>=1.2.0,<2.0.0 or ">=1.2.0 <2.0.0") and "||".
+func ParseRange(s string) (*Range, error) {
+	r := &Range{raw: s}
+	for _, set := range strings.Split(s, "||") {
+		comparators, err := parseComparatorSet(set)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a version range: %v", s, err)
+		}
+		r.sets = append(r.sets, comparators)
+	}
+	return r, nil
+}
+
+// String returns the range as it was parsed
+func (r *Range) String() string {
+	return r.raw
+}
+
+// matchSet checks that v satisfies all comparators.
+// A prerelease version only matches if a comparator has a prerelease of the same MAJOR.MINOR.PATCH.
+func matchSet(set []comparator, v *Version) bool {
+	for _, c := range set {
+		if !c.match(v) {
+			return false
+		}
+	}
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+	for _, c := range set {
+		cv := c.version
+		if len(cv.Prerelease) > 0 && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks that the version satisfies the range
+func (r *Range) Match(v *Version) bool {
+	for _, set := range r.sets {
+		if matchSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Candidate is a version considered on resolving a version range
+type Candidate struct {
+	Version string
+	Matched bool
+}
+
+// MaxSatisfying returns the highest version which satisfies the range, or "" if nothing satisfies it.
+// It also returns all candidates sorted from the highest. Versions which are not semantic versions never match.
+func (r *Range) MaxSatisfying(versions []string) (string, []Candidate) {
+	type parsed struct {
+		raw     string
+		version *Version
+	}
+	list := make([]parsed, 0, len(versions))
+	for _, ver := range versions {
+		v, _ := ParseVersion(ver)
+		list = append(list, parsed{ver, v})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		switch {
+		case list[j].version == nil:
+			return list[i].version != nil
+		case list[i].version == nil:
+			return false
+		default:
+			return list[i].version.Compare(list[j].version) > 0
+		}
+	})
+
+	chosen := ""
+	candidates := make([]Candidate, 0, len(list))
+	for _, p := range list {
+		matched := p.version != nil && r.Match(p.version)
+		if matched && chosen == "" {
+			chosen = p.raw
+		}
+		candidates = append(candidates, Candidate{Version: p.raw, Matched: matched})
+	}
+	return chosen, candidates
+}
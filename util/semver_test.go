@@ -0,0 +1,199 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		version string
+		want    *Version
+	}{
+		{"1.2.3", &Version{Major: 1, Minor: 2, Patch: 3}},
+		{"0.0.0", &Version{}},
+		{"10.20.30", &Version{Major: 10, Minor: 20, Patch: 30}},
+		{"1.2.3-beta.1", &Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"beta", "1"}}},
+		{"1.2.3+build.5", &Version{Major: 1, Minor: 2, Patch: 3, Build: []string{"build", "5"}}},
+		{"1.2.3-rc.1+20180101", &Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}, Build: []string{"20180101"}}},
+		{"1.2.3-0a", &Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"0a"}}},
+	}
+	for _, c := range cases {
+		v, err := ParseVersion(c.version)
+		assert.Nil(t, err, c.version)
+		assert.Equal(t, c.want, v, c.version)
+		assert.Equal(t, c.version, v.String())
+	}
+
+	invalid := []string{"", "1", "1.2", "1.2.x", "v1.2.3", "01.2.3", "1.02.3", "1.2.3-", "1.2.3-01", "1.2.3+", "1.2.3.4", "latest",
+		"18446744073709551616.0.0", "1.18446744073709551616.0", "1.0.18446744073709551616"}
+	for _, ver := range invalid {
+		_, err := ParseVersion(ver)
+		assert.NotNil(t, err, ver)
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// each version has lower precedence than the next one
+	ordered := []string{
+		"0.0.1",
+		"0.1.0",
+		"1.0.0-0",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.9.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		lower, _ := ParseVersion(ordered[i])
+		higher, _ := ParseVersion(ordered[i+1])
+		assert.Equal(t, -1, lower.Compare(higher), "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, 1, higher.Compare(lower), "%s > %s", ordered[i+1], ordered[i])
+		assert.Equal(t, 0, lower.Compare(lower), ordered[i])
+	}
+
+	// build metadata is ignored
+	v1, _ := ParseVersion("1.0.0+build.1")
+	v2, _ := ParseVersion("1.0.0+build.2")
+	assert.Equal(t, 0, v1.Compare(v2))
+}
+
+func TestVersionBump(t *testing.T) {
+	cases := []struct {
+		version string
+		level   string
+		want    string
+	}{
+		{"1.2.3", "major", "2.0.0"},
+		{"1.2.3", "minor", "1.3.0"},
+		{"1.2.3", "patch", "1.2.4"},
+		{"1.2.3+build.5", "patch", "1.2.4"},
+		{"2.0.0-beta.1", "major", "2.0.0"},
+		{"2.1.0-beta.1", "major", "3.0.0"},
+		{"1.3.0-beta.1", "minor", "1.3.0"},
+		{"1.3.1-beta.1", "minor", "1.4.0"},
+		{"1.2.3-beta.1", "patch", "1.2.3"},
+	}
+	for _, c := range cases {
+		v, _ := ParseVersion(c.version)
+		next, err := v.Bump(c.level)
+		assert.Nil(t, err, c.version)
+		assert.Equal(t, c.want, next.String(), "%s by %s", c.version, c.level)
+		assert.Equal(t, c.version, v.String(), c.version)
+	}
+
+	v, _ := ParseVersion("1.2.3")
+	_, err := v.Bump("build")
+	assert.EqualError(t, err, `"build" is not one of major, minor, patch`)
+	_, err = v.Bump("prerelease")
+	assert.NotNil(t, err)
+}
+
+func TestRangeMatch(t *testing.T) {
+	cases := []struct {
+		versionRange string
+		matched      []string
+		unmatched    []string
+	}{
+		{"1.2.3", []string{"1.2.3", "1.2.3+build"}, []string{"1.2.4", "1.2.3-beta"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.2"}},
+		{"1.2.3-beta.1", []string{"1.2.3-beta.1"}, []string{"1.2.3-beta.2", "1.2.3"}},
+		{"*", []string{"0.0.0", "1.2.3", "99.0.0"}, []string{"1.2.3-beta"}},
+		{"x", []string{"1.2.3"}, []string{}},
+		{"", []string{"1.2.3"}, []string{}},
+		{"1", []string{"1.0.0", "1.99.99"}, []string{"0.9.9", "2.0.0", "2.0.0-beta"}},
+		{"1.x", []string{"1.0.0", "1.99.99"}, []string{"2.0.0"}},
+		{"1.2", []string{"1.2.0", "1.2.99"}, []string{"1.1.9", "1.3.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.99"}, []string{"1.3.0"}},
+		{"1.X.x", []string{"1.2.0"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.99"}, []string{"1.2.2", "1.3.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.99"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{"~10.2", []string{"10.2.0", "10.2.5"}, []string{"10.3.0", "1.2.0"}},
+		{"~>1.2.3", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"~1.2.3-beta.2", []string{"1.2.3-beta.2", "1.2.3-beta.4", "1.2.3", "1.2.4"}, []string{"1.2.3-beta.1", "1.2.4-beta.1"}},
+		{"^1.2.3", []string{"1.2.3", "1.99.99"}, []string{"1.2.2", "2.0.0", "2.0.0-0"}},
+		{"^1.12.3", []string{"1.12.3", "1.13.0"}, []string{"1.12.2", "2.0.0"}},
+		{"^0.2.5", []string{"0.2.5", "0.2.99"}, []string{"0.2.4", "0.3.0"}},
+		{"^0.0.4", []string{"0.0.4"}, []string{"0.0.3", "0.0.5"}},
+		{"^1.2", []string{"1.2.0", "1.99.0"}, []string{"1.1.9", "2.0.0"}},
+		{"^1", []string{"1.0.0"}, []string{"2.0.0"}},
+		{"^0.2", []string{"0.2.0", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^0.0.x", []string{"0.0.9"}, []string{"0.1.0"}},
+		{"^0", []string{"0.0.0", "0.99.0"}, []string{"1.0.0"}},
+		{"^1.x", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3-beta.2", []string{"1.2.3-beta.2", "1.2.3-beta.3", "1.9.0"}, []string{"1.2.3-beta.1", "1.2.4-beta.1"}},
+		{"1.2.3 - 2.3.4", []string{"1.2.3", "2.3.4"}, []string{"1.2.2", "2.3.5"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.99"}, []string{"1.1.9", "2.4.0"}},
+		{"1.2.3 - 2", []string{"2.99.0"}, []string{"3.0.0"}},
+		{">=1.2.0,<2.0.0", []string{"1.2.0", "1.99.0"}, []string{"1.1.9", "2.0.0"}},
+		{">=1.2.0 <2.0.0", []string{"1.2.0"}, []string{"2.0.0"}},
+		{">= 1.2.0, < 2.0.0", []string{"1.2.0"}, []string{"2.0.0"}},
+		{">1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"<1.2", []string{"1.1.9"}, []string{"1.2.0"}},
+		{">*", []string{}, []string{"0.0.0", "1.0.0"}},
+		{"^1.2.3 || ^2.1.0", []string{"1.2.3", "2.1.0"}, []string{"2.0.0", "3.0.0"}},
+		{"1.2.3 || >=2.0.0-rc.1 <3.0.0", []string{"1.2.3", "2.0.0-rc.2", "2.5.0"}, []string{"1.2.4", "2.1.0-rc.1", "3.0.0"}},
+	}
+	for _, c := range cases {
+		r, err := ParseRange(c.versionRange)
+		if !assert.Nil(t, err, c.versionRange) {
+			continue
+		}
+		assert.Equal(t, c.versionRange, r.String())
+		for _, ver := range c.matched {
+			v, err := ParseVersion(ver)
+			assert.Nil(t, err, ver)
+			assert.True(t, r.Match(v), "%q should match %q", c.versionRange, ver)
+		}
+		for _, ver := range c.unmatched {
+			v, err := ParseVersion(ver)
+			assert.Nil(t, err, ver)
+			assert.False(t, r.Match(v), "%q should not match %q", c.versionRange, ver)
+		}
+	}
+
+	invalid := []string{"latest", "v1.2.3", "1.2.3.4", "*.1.0", "1.x.3", "1.2-beta", "1.0.", ">=", "1.2.3 - ", "^^1.2.3", "1.2.3 - 2.3.4 - 3.4.5"}
+	for _, ver := range invalid {
+		_, err := ParseRange(ver)
+		assert.NotNil(t, err, ver)
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.10.0", "2.0.0", "1.2.0", "latest", "1.11.0-beta.1", "1.9.5"}
+
+	r, _ := ParseRange("^1.2")
+	chosen, candidates := r.MaxSatisfying(versions)
+	assert.Equal(t, "1.10.0", chosen)
+	assert.Equal(t, []Candidate{
+		{"2.0.0", false},
+		{"1.11.0-beta.1", false},
+		{"1.10.0", true},
+		{"1.9.5", true},
+		{"1.2.0", true},
+		{"1.0.0", false},
+		{"latest", false},
+	}, candidates)
+
+	r, _ = ParseRange("^3")
+	chosen, _ = r.MaxSatisfying(versions)
+	assert.Equal(t, "", chosen)
+
+	chosen, candidates = r.MaxSatisfying(nil)
+	assert.Equal(t, "", chosen)
+	assert.Empty(t, candidates)
+}
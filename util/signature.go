@@ -0,0 +1,97 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// A Signature represents a detached ed25519 signature of a command file.
+// This will works as a part of Binary and Habitat.
+type Signature struct {
+	KeyID string `json:"keyId" yaml:"keyId"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// KeyID returns the identifier of a public key.
+// It is the first 8 bytes of the sha256 digest of the key in hex.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadSigningKey receives path of a file which has a base64 encoded ed25519 private key or seed.
+// It returns the private key.
+func LoadSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	data, err := LoadString(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to load signing key:%v", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("Signing key is not base64 encoded:%v", err)
+	}
+
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("Signing key has invalid length %d", len(key))
+	}
+}
+
+// LoadTrustedKeys receives path of a file which has a base64 encoded ed25519 public key per line.
+// Empty lines and lines starting with # are ignored.
+// It returns the public keys by their key ID.
+func LoadTrustedKeys(keysPath string) (map[string]ed25519.PublicKey, error) {
+	data, err := LoadString(keysPath)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to load trusted keys:%v", err)
+	}
+
+	keys := make(map[string]ed25519.PublicKey)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("Trusted key %q is invalid", line)
+		}
+		keys[KeyID(key)] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+// Sign signs data with the private key.
+func Sign(key ed25519.PrivateKey, data []byte) *Signature {
+	return &Signature{
+		KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+		Value: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+}
+
+// VerifySignature checks that sig is a valid signature of data by one of the trusted keys.
+func VerifySignature(sig *Signature, data []byte, keys map[string]ed25519.PublicKey) error {
+	key, ok := keys[sig.KeyID]
+	if !ok {
+		return fmt.Errorf("Signature key %s is not trusted", sig.KeyID)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return fmt.Errorf("Signature is not base64 encoded:%v", err)
+	}
+
+	if !ed25519.Verify(key, data, value) {
+		return fmt.Errorf("Signature verification failed with key %s", sig.KeyID)
+	}
+	return nil
+}
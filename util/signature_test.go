@@ -0,0 +1,70 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFile(t *testing.T, dir, name string, key []byte) string {
+	keyPath := filepath.Join(dir, name)
+	err := ioutil.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return keyPath
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sd-cmd_signature")
+	defer os.RemoveAll(dir)
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	data := []byte("dummy command")
+
+	// load the key from seed and full private key
+	for _, key := range [][]byte{priv.Seed(), priv} {
+		loaded, err := LoadSigningKey(writeKeyFile(t, dir, "signing.key", key))
+		assert.Nil(t, err)
+		assert.Equal(t, priv, loaded)
+	}
+
+	// failure. invalid signing key
+	_, err := LoadSigningKey(writeKeyFile(t, dir, "invalid.key", []byte("short")))
+	assert.NotNil(t, err)
+	_, err = LoadSigningKey(filepath.Join(dir, "not_exist.key"))
+	assert.NotNil(t, err)
+
+	sig := Sign(priv, data)
+	assert.Equal(t, KeyID(pub), sig.KeyID)
+
+	keysPath := filepath.Join(dir, "trusted.keys")
+	ioutil.WriteFile(keysPath, []byte("# trusted keys\n\n"+
+		base64.StdEncoding.EncodeToString(pub)+"\n"+
+		base64.StdEncoding.EncodeToString(otherPub)+"\n"), 0600)
+	keys, err := LoadTrustedKeys(keysPath)
+	assert.Nil(t, err)
+	assert.Len(t, keys, 2)
+
+	// success
+	assert.Nil(t, VerifySignature(sig, data, keys))
+
+	// failure. the data is tampered
+	assert.NotNil(t, VerifySignature(sig, []byte("tampered command"), keys))
+
+	// failure. the key is not trusted
+	delete(keys, KeyID(pub))
+	assert.NotNil(t, VerifySignature(sig, data, keys))
+
+	// failure. invalid trusted keys
+	ioutil.WriteFile(keysPath, []byte("invalid key\n"), 0600)
+	_, err = LoadTrustedKeys(keysPath)
+	assert.NotNil(t, err)
+}
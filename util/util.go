@@ -1,28 +1,20 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 )
 
 // full command has <COMMAND_NAMESPACE>/<COMMAND_NAME>@<VERSION>.
 // COMMAND_NAMESPACE can only be named with A-Z,a-z,0-9,-,_
 // COMMAND_NAME can only be named with A-Z,a-z,0-9,-,_
-// VERSION can only be a-z0-9.~*^
+// VERSION can only be A-Za-z0-9.~*^+<>=|, and -
 // ex(cmd-namespace/cmd_name@1.0.0)
-var fullCommandRegexp = regexp.MustCompile(`^([\w-]+)\/([\w-]+)@([a-z0-9-~\*\^\.]+)$`)
-
-// xrangesRegexp check VERSION of X-Ranges.
-// ex(1.2.* 1.2 1.x 1 *)
-var xrangesRegexp = regexp.MustCompile(`^(?:(\d+)\.)?(?:(\d+)\.)?([\*x]|\d+)$`)
-
-// tildeRangesRegexp check VERSION of Tilde Ranges
-// ex(~1.2.3 ~1.2 ~1)
-var tildeRangesRegexp = regexp.MustCompile(`^~\d(\.\d)?(\.\d)?$`)
-
-// caretRangesAndPinningRegexp check VERSION of Caret Ranges and Explicit Pinning
-// ex(^1.2.3 ^0.2.5 ^0.0.4 1.2.3 1.5.3)
-var caretRangesAndPinningRegexp = regexp.MustCompile(`^(\^)?\d(\.\d){2}$`)
+var fullCommandRegexp = regexp.MustCompile(`^([\w-]+)\/([\w-]+)@([a-zA-Z0-9~*^.+<>=|, -]+)$`)
 
 // tagRegexp check VERSION of Tags. Tags can only be named with A-Z,a-z,0-9,-,.
 // ex(latest stable feature-abc v1.0.0)
@@ -32,10 +24,12 @@ var tagRegexp = regexp.MustCompile(`^[a-zA-Z][\w-\.]+$`)
 // All value will be omitted if it is not set.
 // This will works as a part of CommandSpec.
 type Habitat struct {
-	Mode    string `json:"mode,omitempty" yaml:"mode,omitempty"`
-	File    string `json:"file,omitempty" yaml:"file,omitempty"`
-	Package string `json:"package,omitempty" yaml:"package,omitempty"`
-	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	Mode      string     `json:"mode,omitempty" yaml:"mode,omitempty"`
+	File      string     `json:"file,omitempty" yaml:"file,omitempty"`
+	Package   string     `json:"package,omitempty" yaml:"package,omitempty"`
+	Command   string     `json:"command,omitempty" yaml:"command,omitempty"`
+	Sha256    string     `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Signature *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
 }
 
 // A Docker represents a set of data for Docker.
@@ -50,25 +44,95 @@ type Docker struct {
 // All value will be omitted if it is not set.
 // This will works as a part of CommandSpec.
 type Binary struct {
-	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	File      string     `json:"file,omitempty" yaml:"file,omitempty"`
+	Sha256    string     `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Signature *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
+	// OS and Arch are GOOS and GOARCH which a variant in Platforms is built for
+	OS   string `json:"os,omitempty" yaml:"os,omitempty"`
+	Arch string `json:"arch,omitempty" yaml:"arch,omitempty"`
+	// Platforms are variants of the binary for each platform. File is ignored if they are set.
+	Platforms []*Binary `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+}
+
+// Platform returns the platform of the binary as OS/Arch
+func (b *Binary) Platform() string {
+	return b.OS + "/" + b.Arch
+}
+
+// SelectPlatform returns the variant of the binary for goos/goarch.
+// It returns the binary itself if it has no variants.
+func (b *Binary) SelectPlatform(goos, goarch string) (*Binary, error) {
+	if len(b.Platforms) == 0 {
+		return b, nil
+	}
+
+	available := make([]string, 0, len(b.Platforms))
+	for _, variant := range b.Platforms {
+		if variant.OS == goos && variant.Arch == goarch {
+			return variant, nil
+		}
+		available = append(available, variant.Platform())
+	}
+	return nil, fmt.Errorf("There is no binary for %s/%s, available platforms are %s",
+		goos, goarch, strings.Join(available, ", "))
+}
+
+// An Archive represents a set of data for Archive.
+// The archive is a gzipped tarball or a zip archive of File, or of Directory built on publish.
+// This will works as a part of CommandSpec.
+type Archive struct {
+	File      string `json:"file,omitempty" yaml:"file,omitempty"`
+	Directory string `json:"-" yaml:"directory,omitempty"`
+	// Path is the local path of the archive built from Directory on publish, which is uploaded as File
+	Path string `json:"-" yaml:"-"`
+	// Entrypoint is the path of the file in the archive which is executed
+	Entrypoint string     `json:"entrypoint" yaml:"entrypoint"`
+	Sha256     string     `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Signature  *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+// A Script represents a set of data for Script.
+// The script is run through Interpreter which is looked up in PATH.
+// This will works as a part of CommandSpec.
+type Script struct {
+	File        string `json:"file,omitempty" yaml:"file,omitempty"`
+	Interpreter string `json:"interpreter" yaml:"interpreter"`
+	// MinVersion is the minimum version of the interpreter such as "3.8"
+	MinVersion string     `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+	Sha256     string     `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Signature  *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+// An EnvVar represents an environment variable which the command depends on.
+// Default is applied if the variable is not set. A required variable without Default must be set.
+type EnvVar struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
 }
 
 // A CommandSpec represents a set of data for commands.
 // Some value will be omitted if it is not set.
 type CommandSpec struct {
-	ID           int      `json:"id,omitempty" yaml:"id,omitempty"`
-	Namespace    string   `json:"namespace" yaml:"namespace"`
-	Name         string   `json:"name" yaml:"name"`
-	Description  string   `json:"description" yaml:"description"`
-	Usage        string   `json:"usage,omitempty" yaml:"usage,omitempty"`
-	Maintainer   string   `json:"maintainer" yaml:"maintainer"`
-	Version      string   `json:"version" yaml:"version"`
-	Format       string   `json:"format" yaml:"format"`
-	Habitat      *Habitat `json:"habitat,omitempty" yaml:"habitat,omitempty"`
-	Docker       *Docker  `json:"docker,omitempty" yaml:"docker,omitempty"`
-	Binary       *Binary  `json:"binary,omitempty" yaml:"binary,omitempty"`
-	PipelineID   int      `json:"pipelineId,omitempty" yaml:"pipelineId,omitempty"`
-	SpecYamlPath string   `json:"-" yaml:"-"`
+	ID           int         `json:"id,omitempty" yaml:"id,omitempty"`
+	Namespace    string      `json:"namespace" yaml:"namespace"`
+	Name         string      `json:"name" yaml:"name"`
+	Description  string      `json:"description" yaml:"description"`
+	Usage        string      `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Args         *ArgsSchema `json:"args,omitempty" yaml:"args,omitempty"`
+	Maintainer   string      `json:"maintainer" yaml:"maintainer"`
+	Version      string      `json:"version" yaml:"version"`
+	Format       string      `json:"format" yaml:"format"`
+	Habitat      *Habitat    `json:"habitat,omitempty" yaml:"habitat,omitempty"`
+	Docker       *Docker     `json:"docker,omitempty" yaml:"docker,omitempty"`
+	Binary       *Binary     `json:"binary,omitempty" yaml:"binary,omitempty"`
+	Archive      *Archive    `json:"archive,omitempty" yaml:"archive,omitempty"`
+	Script       *Script     `json:"script,omitempty" yaml:"script,omitempty"`
+	PipelineID   int         `json:"pipelineId,omitempty" yaml:"pipelineId,omitempty"`
+	Timeout      string      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Env          []*EnvVar   `json:"env,omitempty" yaml:"env,omitempty"`
+	SpecYamlPath string      `json:"-" yaml:"-"`
 }
 
 // PayloadYaml represents a set of data for posting command.
@@ -100,20 +164,20 @@ type TagResponse struct {
 	Version   string `json:"version"`
 }
 
+// A CommandVersion represents a published version of a command and the tags pointing at it
+type CommandVersion struct {
+	Version    string    `json:"version"`
+	CreateTime time.Time `json:"createTime"`
+	Tags       []string  `json:"tags"`
+}
+
+// checkVersion checks that ver is a version range (see ParseRange) or a tag.
+// Upper case letters are allowed in ranges (1.X, 1.0.0-RC.1) only, a tag in a full command is lower case.
 func checkVersion(ver string) bool {
-	if caretRangesAndPinningRegexp.Match([]byte(ver)) {
+	if _, err := ParseRange(ver); err == nil {
 		return true
 	}
-	if tildeRangesRegexp.Match([]byte(ver)) {
-		return true
-	}
-	if xrangesRegexp.Match([]byte(ver)) {
-		return true
-	}
-	if tagRegexp.Match([]byte(ver)) {
-		return true
-	}
-	return false
+	return ver == strings.ToLower(ver) && tagRegexp.Match([]byte(ver))
 }
 
 // SplitCmd splits full command to namespace, command, version.
@@ -156,3 +220,9 @@ func SplitCmdWithSearch(cmds []string) (smallSpec *CommandSpec, pos int, err err
 func ValidateTagName(tag string) bool {
 	return tagRegexp.Match([]byte(tag))
 }
+
+// Sha256Sum returns the hex encoded sha256 digest of data.
+func Sha256Sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
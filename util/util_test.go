@@ -27,6 +27,14 @@ func TestSplitCmd(t *testing.T) {
 		{"foo/bar@feature-abc", "foo", "bar", "feature-abc"},
 		{"Foo/Bar@feature-abc", "Foo", "Bar", "feature-abc"},
 		{"foo/bar@v1.0.0", "foo", "bar", "v1.0.0"},
+		{"foo/bar@^1.12.3", "foo", "bar", "^1.12.3"},
+		{"foo/bar@~10.2", "foo", "bar", "~10.2"},
+		{"foo/bar@1.2.3-beta.1+build.5", "foo", "bar", "1.2.3-beta.1+build.5"},
+		{"foo/bar@1.2.3 - 2.3.4", "foo", "bar", "1.2.3 - 2.3.4"},
+		{"foo/bar@>=1.2.0,<2.0.0", "foo", "bar", ">=1.2.0,<2.0.0"},
+		{"foo/bar@^1.2.3 || ^2.0.0", "foo", "bar", "^1.2.3 || ^2.0.0"},
+		{"foo/bar@1.X", "foo", "bar", "1.X"},
+		{"foo/bar@1.0.0-RC.1", "foo", "bar", "1.0.0-RC.1"},
 	}
 
 	for _, c := range fullCommands {
@@ -67,6 +75,9 @@ func TestSplitCmd(t *testing.T) {
 		"foo/bar@aaa_bbb",
 		"foo/bar@-tag",
 		"foo/bar@Tag",
+		"foo/bar@1.x.3",
+		"foo/bar@>=1.2.0,<",
+		"foo/bar@1.2-beta",
 	}
 	for _, cmdName := range fullCommandNames {
 		_, err := SplitCmd(cmdName)
@@ -92,3 +103,40 @@ func TestSplitCmdWithSearch(t *testing.T) {
 		t.Errorf("err=nil, want error")
 	}
 }
+
+func TestSha256Sum(t *testing.T) {
+	// sha256 of "hello"
+	expected := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if actual := Sha256Sum([]byte("hello")); actual != expected {
+		t.Errorf("digest=%q, want %q", actual, expected)
+	}
+}
+
+func TestSelectPlatform(t *testing.T) {
+	// a binary without variants
+	single := &Binary{File: "sd-step"}
+	if selected, err := single.SelectPlatform("linux", "arm64"); err != nil || selected != single {
+		t.Errorf("selected=%v, err=%v, want the binary itself", selected, err)
+	}
+
+	binary := &Binary{
+		Platforms: []*Binary{
+			{OS: "linux", Arch: "amd64", File: "sd-step-linux-amd64"},
+			{OS: "linux", Arch: "arm64", File: "sd-step-linux-arm64"},
+			{OS: "darwin", Arch: "amd64", File: "sd-step-darwin-amd64"},
+		},
+	}
+	selected, err := binary.SelectPlatform("linux", "arm64")
+	if err != nil {
+		t.Errorf("err=%q, want nil", err)
+	} else if selected.File != "sd-step-linux-arm64" {
+		t.Errorf("file=%q, want %q", selected.File, "sd-step-linux-arm64")
+	}
+
+	// failure
+	expected := "There is no binary for windows/amd64, available platforms are linux/amd64, linux/arm64, darwin/amd64"
+	_, err = binary.SelectPlatform("windows", "amd64")
+	if err == nil || err.Error() != expected {
+		t.Errorf("err=%v, want %q", err, expected)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 
+	"gopkg.in/yaml.v2"
+
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
 	"github.com/screwdriver-cd/sd-cmd/util"
 )
@@ -16,8 +18,46 @@ type Validator struct {
 	sdAPI      api.API
 }
 
+// validateScript returns the reasons why the script of the command spec is not valid.
+// The interpreter of a script must be declared because it is not in the script itself.
+func validateScript(yamlString string) []string {
+	spec := new(util.CommandSpec)
+	// a yaml which can not be parsed is reported by Screwdriver API
+	if err := yaml.Unmarshal([]byte(yamlString), spec); err != nil || spec.Format != "script" {
+		return nil
+	}
+
+	var reasons []string
+	if spec.Script == nil || spec.Script.Interpreter == "" {
+		reasons = append(reasons, "The interpreter of the script is not declared")
+	} else if spec.Script.MinVersion != "" {
+		if _, err := util.ParseRange(">=" + spec.Script.MinVersion); err != nil {
+			reasons = append(reasons, fmt.Sprintf("The minimum version of the interpreter %q is not a version", spec.Script.MinVersion))
+		}
+	}
+	return reasons
+}
+
+// validateArgs returns the reasons why the argument schema of the command spec is not valid
+func validateArgs(yamlString string) []string {
+	spec := new(util.CommandSpec)
+	if err := yaml.Unmarshal([]byte(yamlString), spec); err != nil {
+		return nil
+	}
+	return spec.Args.Validate()
+}
+
 // Run is a method to validate yaml.
 func (v *Validator) Run() error {
+	reasons := append(validateScript(v.yamlString), validateArgs(v.yamlString)...)
+	if len(reasons) != 0 {
+		errorMessage := ""
+		for _, reason := range reasons {
+			errorMessage += reason + "\n"
+		}
+		return fmt.Errorf("Command is not valid for the following reasons:\n%v", errorMessage)
+	}
+
 	validateResponse, err := v.sdAPI.ValidateCommand(v.yamlString)
 	if err != nil {
 		return fmt.Errorf("Post failed:%v", err)
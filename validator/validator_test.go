@@ -3,6 +3,8 @@ package validator
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
 	"github.com/screwdriver-cd/sd-cmd/util"
 )
@@ -13,6 +15,22 @@ func (d *dummySDAPIValidator) GetCommand(spec *util.CommandSpec) (*util.CommandS
 	return nil, nil
 }
 
+func (d *dummySDAPIValidator) GetCommandVersions(smallSpec *util.CommandSpec) ([]string, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPIValidator) GetCommandVersionDetails(smallSpec *util.CommandSpec) ([]*util.CommandVersion, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPIValidator) ListCommands(namespace string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
+func (d *dummySDAPIValidator) SearchCommands(keyword string, page, count int) ([]*util.CommandSpec, error) {
+	return nil, nil
+}
+
 func (d *dummySDAPIValidator) PostCommand(smallSpec *util.CommandSpec) (*util.CommandSpec, error) {
 	return nil, nil
 }
@@ -60,3 +78,57 @@ func TestRun(t *testing.T) {
 		t.Errorf("err=%v, want nil", err)
 	}
 }
+
+func TestRunScript(t *testing.T) {
+	sdapi := api.API(new(dummySDAPIValidator))
+
+	// success
+	v, err := New(sdapi, []string{"-f", "../testdata/yaml/script-sd-command.yaml"})
+	if assert.Nil(t, err) {
+		assert.Nil(t, v.Run())
+	}
+
+	// failure. the interpreter is not declared
+	v, err = New(sdapi, []string{"-f", "../testdata/yaml/script-no-interpreter-sd-command.yaml"})
+	if assert.Nil(t, err) {
+		assert.EqualError(t, v.Run(), "Command is not valid for the following reasons:\nThe interpreter of the script is not declared\n")
+	}
+}
+
+func TestValidateScript(t *testing.T) {
+	assert.Empty(t, validateScript("format: binary\nbinary:\n    file: ./hello\n"))
+	assert.Empty(t, validateScript("format: script\nscript:\n    interpreter: python3\n    minVersion: 3.8\n"))
+	assert.Empty(t, validateScript("format: [invalid"))
+	assert.Equal(t, []string{"The interpreter of the script is not declared"}, validateScript("format: script\n"))
+	assert.Equal(t, []string{`The minimum version of the interpreter "3.x.1" is not a version`},
+		validateScript("format: script\nscript:\n    interpreter: python3\n    minVersion: 3.x.1\n"))
+}
+
+func TestValidateArgs(t *testing.T) {
+	assert.Empty(t, validateArgs("format: binary\n"))
+	assert.Empty(t, validateArgs("format: [invalid"))
+	assert.Empty(t, validateArgs(`format: binary
+args:
+    flags:
+        - name: output
+          enum: [json, yaml]
+    arguments:
+        - name: target
+          required: true
+        - name: files
+          variadic: true
+`))
+	assert.Equal(t, []string{
+		`The type "float" of the flag count is not one of string, int, number, bool`,
+		"The required argument target follows the optional argument mode",
+	}, validateArgs(`format: binary
+args:
+    flags:
+        - name: count
+          type: float
+    arguments:
+        - name: mode
+        - name: target
+          required: true
+`))
+}
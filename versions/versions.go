@@ -0,0 +1,141 @@
+// Package versions lists the published versions of a command and their tags.
+package versions
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+// Versions is a type to list the versions of a command
+type Versions struct {
+	smallSpec *util.CommandSpec
+	sdAPI     api.API
+	// versionRange filters the versions unless it is nil
+	versionRange        *util.Range
+	isExcludePrerelease bool
+	isJSON              bool
+	out                 io.Writer
+}
+
+// New generates new Versions.
+// args is expected as [options..., "namespace/name"]
+func New(api api.API, args []string) (v *Versions, err error) {
+	v = &Versions{sdAPI: api, out: os.Stdout}
+
+	fs := flag.NewFlagSet("versions", flag.ContinueOnError)
+	fs.BoolVar(&v.isExcludePrerelease, "no-prerelease", false, "Exclude prerelease versions")
+	rangeString := fs.String("range", "", "Show the versions which satisfy the version range only (e.g. ^1.2)")
+	fs.BoolVar(&v.isJSON, "json", false, "Output the versions as JSON")
+	err = fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse input args:%v", err)
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("namespace/name is required")
+	}
+
+	commandName := strings.Split(fs.Arg(0), "/") // namespace/name
+	if len(commandName) != 2 || commandName[0] == "" || commandName[1] == "" {
+		return nil, fmt.Errorf("%v is invalid command name", fs.Arg(0))
+	}
+	v.smallSpec = &util.CommandSpec{
+		Namespace: commandName[0],
+		Name:      commandName[1],
+	}
+
+	if *rangeString != "" {
+		v.versionRange, err = util.ParseRange(*rangeString)
+		if err != nil {
+			return nil, fmt.Errorf("%v is invalid version range: %v", *rangeString, err)
+		}
+	}
+	return
+}
+
+// parsedVersion is a published version with its semantic version, which is nil if it is not a semantic version
+type parsedVersion struct {
+	*util.CommandVersion
+	semver *util.Version
+}
+
+// filter returns the versions which pass the filters sorted from the highest.
+// Versions which are not semantic versions are placed last, and never satisfy a version range.
+func (v *Versions) filter(versions []*util.CommandVersion) []*util.CommandVersion {
+	list := make([]parsedVersion, 0, len(versions))
+	for _, version := range versions {
+		semver, _ := util.ParseVersion(version.Version)
+		if v.versionRange != nil && (semver == nil || !v.versionRange.Match(semver)) {
+			continue
+		}
+		if v.isExcludePrerelease && semver != nil && len(semver.Prerelease) > 0 {
+			continue
+		}
+		list = append(list, parsedVersion{version, semver})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		switch {
+		case list[j].semver == nil:
+			return list[i].semver != nil
+		case list[i].semver == nil:
+			return false
+		default:
+			return list[i].semver.Compare(list[j].semver) > 0
+		}
+	})
+
+	filtered := make([]*util.CommandVersion, 0, len(list))
+	for _, p := range list {
+		filtered = append(filtered, p.CommandVersion)
+	}
+	return filtered
+}
+
+func (v *Versions) print(versions []*util.CommandVersion) error {
+	if len(versions) == 0 {
+		fmt.Fprintln(v.out, "No version is found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(v.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tPUBLISHED\tTAGS")
+	for _, version := range versions {
+		published, tags := "-", "-"
+		if !version.CreateTime.IsZero() {
+			published = version.CreateTime.UTC().Format(time.RFC3339)
+		}
+		if len(version.Tags) > 0 {
+			tags = strings.Join(version.Tags, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", version.Version, published, tags)
+	}
+	return w.Flush()
+}
+
+// Run lists the versions of the command
+func (v *Versions) Run() error {
+	versions, err := v.sdAPI.GetCommandVersionDetails(v.smallSpec)
+	if err != nil {
+		return fmt.Errorf("Failed to get versions: %v", err)
+	}
+	versions = v.filter(versions)
+
+	if !v.isJSON {
+		return v.print(versions)
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert the versions to json: %v", err)
+	}
+	fmt.Fprintln(v.out, string(data))
+	return nil
+}
@@ -0,0 +1,119 @@
+package versions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/screwdriver-cd/sd-cmd/screwdriver/api"
+	"github.com/screwdriver-cd/sd-cmd/util"
+)
+
+const (
+	dummyVersions = `[
+	{"namespace":"foo","name":"bar","version":"1.2.0","createTime":"2020-01-02T03:04:05.000Z"},
+	{"namespace":"foo","name":"bar","version":"1.10.0","createTime":"2020-03-02T03:04:05.000Z"},
+	{"namespace":"foo","name":"bar","version":"2.0.0-beta.1","createTime":"2020-04-02T03:04:05.000Z"},
+	{"namespace":"foo","name":"bar","version":"1.9.3"}
+]`
+	dummyTags = `[
+	{"namespace":"foo","name":"bar","tag":"stable","version":"1.10.0"},
+	{"namespace":"foo","name":"bar","tag":"latest","version":"1.10.0"},
+	{"namespace":"foo","name":"bar","tag":"beta","version":"2.0.0-beta.1"}
+]`
+)
+
+// newFakeAPI returns API whose server responds with versions and tags of foo/bar
+func newFakeAPI(t *testing.T) api.API {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v4/commands/foo/bar":
+			fmt.Fprint(w, dummyVersions)
+		case "/v4/commands/foo/bar/tags":
+			fmt.Fprint(w, dummyTags)
+		default:
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"statusCode": 404,"error": "Not Found","message": "Command does not exist"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return api.New(server.URL+"/v4/", "fake-sd-token")
+}
+
+func run(t *testing.T, args ...string) (string, error) {
+	v, err := New(newFakeAPI(t), args)
+	if err != nil {
+		t.Fatalf("err=%v, want nil", err)
+	}
+	out := new(bytes.Buffer)
+	v.out = out
+	err = v.Run()
+	return out.String(), err
+}
+
+func TestNew(t *testing.T) {
+	v, err := New(nil, []string{"-no-prerelease", "-range", "^1.2", "-json", "foo/bar"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "foo", v.smallSpec.Namespace)
+		assert.Equal(t, "bar", v.smallSpec.Name)
+		assert.True(t, v.isExcludePrerelease)
+		assert.True(t, v.isJSON)
+		assert.Equal(t, "^1.2", v.versionRange.String())
+	}
+
+	// failure. no command
+	_, err = New(nil, []string{})
+	assert.NotNil(t, err)
+
+	// failure. invalid command name
+	_, err = New(nil, []string{"foo/bar/baz"})
+	assert.NotNil(t, err)
+
+	// failure. invalid range
+	_, err = New(nil, []string{"-range", "^a.b", "foo/bar"})
+	assert.NotNil(t, err)
+}
+
+func TestRun(t *testing.T) {
+	out, err := run(t, "foo/bar")
+	assert.Nil(t, err)
+	expected := `VERSION       PUBLISHED             TAGS
+2.0.0-beta.1  2020-04-02T03:04:05Z  beta
+1.10.0        2020-03-02T03:04:05Z  latest,stable
+1.9.3         -                     -
+1.2.0         2020-01-02T03:04:05Z  -
+`
+	assert.Equal(t, expected, out)
+
+	// filters
+	out, err = run(t, "-no-prerelease", "foo/bar")
+	assert.Nil(t, err)
+	assert.NotContains(t, out, "2.0.0-beta.1")
+
+	out, err = run(t, "-range", "~1.9 || 1.2.x", "foo/bar")
+	assert.Nil(t, err)
+	assert.Equal(t, "VERSION  PUBLISHED             TAGS\n1.9.3    -                     -\n1.2.0    2020-01-02T03:04:05Z  -\n", out)
+
+	out, err = run(t, "-range", "^3", "foo/bar")
+	assert.Nil(t, err)
+	assert.Equal(t, "No version is found\n", out)
+
+	// json
+	out, err = run(t, "-json", "-range", "^1.10", "foo/bar")
+	assert.Nil(t, err)
+	var versions []*util.CommandVersion
+	if assert.Nil(t, json.Unmarshal([]byte(out), &versions)) && assert.Len(t, versions, 1) {
+		assert.Equal(t, "1.10.0", versions[0].Version)
+		assert.Equal(t, []string{"latest", "stable"}, versions[0].Tags)
+	}
+
+	// failure. the command does not exist
+	v, _ := New(newFakeAPI(t), []string{"foo/baz"})
+	assert.EqualError(t, v.Run(), "Failed to get versions: Screwdriver API 404 Not Found: Command does not exist")
+}